@@ -0,0 +1,78 @@
+// canvasrender 將 JSON 文件繪製成 PNG 或 SVG，不需要瀏覽器。
+//
+// 用法：
+//
+//	canvasrender -in drawing.json -out drawing.png
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"canvas-demo/internal/canvas/document"
+	"canvas-demo/internal/canvas/layer"
+	"canvas-demo/internal/canvas/raster"
+	"canvas-demo/internal/canvas/shape"
+	"canvas-demo/internal/canvas/svg"
+)
+
+// padding 內容四周保留的空白
+const padding = 10.0
+
+func main() {
+	in := flag.String("in", "", "輸入的 JSON 文件")
+	out := flag.String("out", "", "輸出檔案（.png 或 .svg）")
+	flag.Parse()
+
+	if *in == "" || *out == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*in, *out); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(in, out string) error {
+	data, err := os.ReadFile(in)
+	if err != nil {
+		return err
+	}
+
+	doc, err := document.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+	shapes := layer.VisibleShapes(doc.Layers)
+	if len(shapes) == 0 {
+		return fmt.Errorf("%s: document is empty", in)
+	}
+
+	view := shape.UnionBounds(shapes).Inflate(padding)
+
+	ext := strings.ToLower(filepath.Ext(out))
+	if ext != ".png" && ext != ".svg" {
+		return fmt.Errorf("%s: unsupported output format", out)
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+
+	if ext == ".png" {
+		err = raster.EncodePNGLayers(f, doc.Layers, view)
+	} else {
+		err = svg.EncodeLayers(f, doc.Layers, view)
+	}
+
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,125 @@
+//go:build js && wasm
+
+package canvas
+
+import (
+	"fmt"
+	"sort"
+
+	"canvas-demo/internal/canvas/shape"
+)
+
+// 對齊的位置
+const (
+	alignLeft   = "left"
+	alignCenter = "center" // 垂直的中線
+	alignRight  = "right"
+	alignTop    = "top"
+	alignMiddle = "middle" // 水平的中線
+	alignBottom = "bottom"
+)
+
+// 平均分配的方向
+const (
+	distributeHorizontal = "horizontal"
+	distributeVertical   = "vertical"
+)
+
+// AlignSelected 將選取的形狀對齊到合併邊界框的一邊或中線：
+// left、center、right、top、middle 或 bottom。至少需要選取兩個形狀
+func (cm *CanvasManager) AlignSelected(edge string) error {
+	var offset func(b, all shape.Bounds) shape.Point
+	switch edge {
+	case alignLeft:
+		offset = func(b, all shape.Bounds) shape.Point { return shape.Point{X: all.X - b.X} }
+	case alignCenter:
+		offset = func(b, all shape.Bounds) shape.Point { return shape.Point{X: all.Center().X - b.Center().X} }
+	case alignRight:
+		offset = func(b, all shape.Bounds) shape.Point { return shape.Point{X: all.X + all.Width - b.X - b.Width} }
+	case alignTop:
+		offset = func(b, all shape.Bounds) shape.Point { return shape.Point{Y: all.Y - b.Y} }
+	case alignMiddle:
+		offset = func(b, all shape.Bounds) shape.Point { return shape.Point{Y: all.Center().Y - b.Center().Y} }
+	case alignBottom:
+		offset = func(b, all shape.Bounds) shape.Point { return shape.Point{Y: all.Y + all.Height - b.Y - b.Height} }
+	default:
+		return fmt.Errorf("unknown alignment %q", edge)
+	}
+	if len(cm.selection) < 2 {
+		return nil
+	}
+
+	all := cm.selectionBounds()
+	shapes := cm.selectedShapes()
+	offsets := make([]shape.Point, len(shapes))
+	for i, s := range shapes {
+		offsets[i] = offset(s.GetBounds(), all)
+	}
+	cm.arrange(shapes, offsets)
+	return nil
+}
+
+// DistributeSelected 在最外側的兩個形狀之間平均分配選取形狀的間距：
+// horizontal 或 vertical。至少需要選取三個形狀
+func (cm *CanvasManager) DistributeSelected(direction string) error {
+	var horizontal bool
+	switch direction {
+	case distributeHorizontal:
+		horizontal = true
+	case distributeVertical:
+	default:
+		return fmt.Errorf("unknown distribution %q", direction)
+	}
+	if len(cm.selection) < 3 {
+		return nil
+	}
+
+	// 依形狀在軸上的起點排序，頭尾兩個形狀不動
+	shapes := cm.selectedShapes()
+	bounds := make(map[shape.Shape]shape.Bounds, len(shapes))
+	for _, s := range shapes {
+		bounds[s] = s.GetBounds()
+	}
+	sort.SliceStable(shapes, func(i, j int) bool {
+		a, _ := along(bounds[shapes[i]], horizontal)
+		b, _ := along(bounds[shapes[j]], horizontal)
+		return a < b
+	})
+
+	first, _ := along(bounds[shapes[0]], horizontal)
+	_, last := along(bounds[shapes[len(shapes)-1]], horizontal)
+	total := 0.0
+	for _, s := range shapes {
+		lo, hi := along(bounds[s], horizontal)
+		total += hi - lo
+	}
+	gap := (last - first - total) / float64(len(shapes)-1)
+
+	offsets := make([]shape.Point, len(shapes))
+	next := first
+	for i, s := range shapes {
+		lo, hi := along(bounds[s], horizontal)
+		offsets[i] = axisPoint(next-lo, 0, horizontal)
+		next += hi - lo + gap
+	}
+	cm.arrange(shapes, offsets)
+	return nil
+}
+
+// arrange 將形狀各自移動 offsets，有任何移動時記錄為一個可復原的步驟
+func (cm *CanvasManager) arrange(shapes []shape.Shape, offsets []shape.Point) {
+	moved := false
+	for _, o := range offsets {
+		if o != (shape.Point{}) {
+			moved = true
+			break
+		}
+	}
+	if !moved {
+		return
+	}
+
+	cm.stopTextEdit()
+	cm.history.Execute(&arrangeCommand{shapes: shapes, offsets: offsets})
+	cm.redraw()
+}
@@ -0,0 +1,141 @@
+//go:build js && wasm
+
+package canvas
+
+import (
+	"reflect"
+	"testing"
+
+	"canvas-demo/internal/canvas/shape"
+)
+
+// addRects 在目前的圖層加入矩形並全部選取，回傳的順序與 boxes 相同
+func addRects(cm *CanvasManager, boxes ...shape.Bounds) []shape.Shape {
+	shapes := make([]shape.Shape, len(boxes))
+	for i, b := range boxes {
+		r := shape.NewRect(shape.Style{StrokeStyle: "#000000", LineWidth: 1})
+		r.SetCorners(shape.Point{X: b.X, Y: b.Y}, shape.Point{X: b.X + b.Width, Y: b.Y + b.Height})
+		cm.insertShape(cm.activeLayer, -1, r)
+		shapes[i] = r
+	}
+	cm.setSelection(shapes)
+	return shapes
+}
+
+// shapeBounds 回傳每個形狀的邊界框
+func shapeBounds(shapes []shape.Shape) []shape.Bounds {
+	bounds := make([]shape.Bounds, len(shapes))
+	for i, s := range shapes {
+		bounds[i] = s.GetBounds()
+	}
+	return bounds
+}
+
+func TestAlignSelected(t *testing.T) {
+	// 合併的邊界框為 (10, 20) 到 (120, 210)，中線在 x = 65、y = 115
+	boxes := []shape.Bounds{box(10, 20, 30, 40), box(100, 50, 20, 20), box(50, 200, 60, 10)}
+	tests := []struct {
+		edge string
+		want []shape.Bounds
+	}{
+		{alignLeft, []shape.Bounds{box(10, 20, 30, 40), box(10, 50, 20, 20), box(10, 200, 60, 10)}},
+		{alignCenter, []shape.Bounds{box(50, 20, 30, 40), box(55, 50, 20, 20), box(35, 200, 60, 10)}},
+		{alignRight, []shape.Bounds{box(90, 20, 30, 40), box(100, 50, 20, 20), box(60, 200, 60, 10)}},
+		{alignTop, []shape.Bounds{box(10, 20, 30, 40), box(100, 20, 20, 20), box(50, 20, 60, 10)}},
+		{alignMiddle, []shape.Bounds{box(10, 95, 30, 40), box(100, 105, 20, 20), box(50, 110, 60, 10)}},
+		{alignBottom, []shape.Bounds{box(10, 170, 30, 40), box(100, 190, 20, 20), box(50, 200, 60, 10)}},
+	}
+	for _, tt := range tests {
+		cm := newTestManager()
+		shapes := addRects(cm, boxes...)
+		if err := cm.AlignSelected(tt.edge); err != nil {
+			t.Fatalf("AlignSelected(%q): %v", tt.edge, err)
+		}
+		if got := shapeBounds(shapes); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("AlignSelected(%q) = %v, want %v", tt.edge, got, tt.want)
+		}
+
+		// 整個對齊為一個復原步驟
+		cm.Undo()
+		if got := shapeBounds(shapes); !reflect.DeepEqual(got, boxes) {
+			t.Errorf("%q: after Undo = %v, want %v", tt.edge, got, boxes)
+		}
+		if cm.history.CanUndo() {
+			t.Errorf("%q: more than one undo step", tt.edge)
+		}
+	}
+}
+
+func TestDistributeSelected(t *testing.T) {
+	tests := []struct {
+		name      string
+		direction string
+		boxes     []shape.Bounds
+		want      []shape.Bounds
+	}{
+		{"horizontal", distributeHorizontal,
+			[]shape.Bounds{box(0, 0, 20, 10), box(50, 30, 20, 10), box(200, 5, 40, 10)},
+			[]shape.Bounds{box(0, 0, 20, 10), box(100, 30, 20, 10), box(200, 5, 40, 10)}},
+		{"vertical", distributeVertical,
+			[]shape.Bounds{box(0, 0, 10, 20), box(30, 10, 10, 40), box(5, 170, 10, 30)},
+			[]shape.Bounds{box(0, 0, 10, 20), box(30, 75, 10, 40), box(5, 170, 10, 30)}},
+		// 依位置而不是選取的順序排列，重疊的形狀也拉開成相同的間距
+		{"unsorted and overlapping", distributeHorizontal,
+			[]shape.Bounds{box(200, 0, 40, 10), box(150, 0, 30, 10), box(0, 0, 20, 10), box(10, 0, 30, 10)},
+			[]shape.Bounds{box(200, 0, 40, 10), box(130, 0, 30, 10), box(0, 0, 20, 10), box(60, 0, 30, 10)}},
+	}
+	for _, tt := range tests {
+		cm := newTestManager()
+		shapes := addRects(cm, tt.boxes...)
+		if err := cm.DistributeSelected(tt.direction); err != nil {
+			t.Fatalf("%s: DistributeSelected(%q): %v", tt.name, tt.direction, err)
+		}
+		if got := shapeBounds(shapes); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: DistributeSelected(%q) = %v, want %v", tt.name, tt.direction, got, tt.want)
+		}
+
+		// 整個分配為一個復原步驟
+		cm.Undo()
+		if got := shapeBounds(shapes); !reflect.DeepEqual(got, tt.boxes) {
+			t.Errorf("%s: after Undo = %v, want %v", tt.name, got, tt.boxes)
+		}
+		if cm.history.CanUndo() {
+			t.Errorf("%s: more than one undo step", tt.name)
+		}
+	}
+}
+
+// 選取的形狀不夠或位置已經正確時不移動，也不記錄復原步驟
+func TestArrangeNoOp(t *testing.T) {
+	cm := newTestManager()
+	one := addRects(cm, box(10, 10, 20, 20))
+	if err := cm.AlignSelected(alignRight); err != nil {
+		t.Fatal(err)
+	}
+	two := addRects(cm, box(0, 0, 20, 10), box(100, 50, 20, 10))
+	if err := cm.DistributeSelected(distributeHorizontal); err != nil {
+		t.Fatal(err)
+	}
+	if got := shapeBounds(append(one, two...)); !reflect.DeepEqual(got, []shape.Bounds{box(10, 10, 20, 20), box(0, 0, 20, 10), box(100, 50, 20, 10)}) {
+		t.Errorf("shapes moved: %v", got)
+	}
+
+	addRects(cm, box(0, 0, 20, 10), box(0, 50, 20, 10))
+	if err := cm.AlignSelected(alignLeft); err != nil {
+		t.Fatal(err)
+	}
+	addRects(cm, box(0, 0, 10, 10), box(20, 0, 10, 10), box(40, 0, 10, 10))
+	if err := cm.DistributeSelected(distributeHorizontal); err != nil {
+		t.Fatal(err)
+	}
+	if cm.history.CanUndo() {
+		t.Error("no-op recorded an undo step")
+	}
+
+	if err := cm.AlignSelected("diagonal"); err == nil {
+		t.Error("AlignSelected(diagonal) succeeded")
+	}
+	if err := cm.DistributeSelected("diagonal"); err == nil {
+		t.Error("DistributeSelected(diagonal) succeeded")
+	}
+}
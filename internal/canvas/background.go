@@ -0,0 +1,162 @@
+//go:build js && wasm
+
+package canvas
+
+import (
+	"fmt"
+	"math"
+
+	"canvas-demo/internal/canvas/layer"
+	"canvas-demo/internal/canvas/shape"
+)
+
+// 背景圖樣
+const (
+	backgroundNone  = "none"
+	backgroundDots  = "dots"  // 每個間隔一個點
+	backgroundLines = "lines" // 方格線
+)
+
+const (
+	backgroundSpacing    = 20.0 // 背景圖樣在畫布座標中的間隔
+	minBackgroundSpacing = 16.0 // 縮小時背景圖樣在螢幕上的最小間隔，太密時間隔加倍
+	backgroundColor      = "#d0d0d0"
+	cullMargin           = 32.0 // 判斷形狀是否在畫面內時多保留的範圍，涵蓋線寬
+)
+
+// 小地圖的大小與位置（螢幕像素）
+const (
+	minimapWidth  = 160.0
+	minimapHeight = 120.0
+	minimapMargin = 10.0
+)
+
+// SetBackground 設置跟著畫面移動的背景圖樣：none、dots 或 lines
+func (cm *CanvasManager) SetBackground(pattern string) error {
+	switch pattern {
+	case backgroundNone, backgroundDots, backgroundLines:
+	default:
+		return fmt.Errorf("unknown background %q", pattern)
+	}
+	cm.background = pattern
+	cm.redraw()
+	return nil
+}
+
+// SetMinimapVisible 顯示或隱藏小地圖
+func (cm *CanvasManager) SetMinimapVisible(visible bool) {
+	cm.showMinimap = visible
+	cm.redraw()
+}
+
+// drawBackground 在範圍 area 內繪製背景圖樣（畫布座標），顯示格線時以格線取代背景圖樣
+func (cm *CanvasManager) drawBackground(area shape.Bounds) {
+	if cm.grid.Visible {
+		cm.grid.Draw(cm.renderer, area, cm.view.scale)
+		return
+	}
+	if cm.background == backgroundNone {
+		return
+	}
+
+	spacing := backgroundSpacing
+	for spacing*cm.view.scale < minBackgroundSpacing {
+		spacing *= 2
+	}
+
+	left := math.Floor(area.X/spacing) * spacing
+	top := math.Floor(area.Y/spacing) * spacing
+	right := area.X + area.Width
+	bottom := area.Y + area.Height
+
+	r := cm.renderer
+	r.BeginPath()
+	switch cm.background {
+	case backgroundDots:
+		size := cm.view.screenLength(2)
+		for x := left; x <= right; x += spacing {
+			for y := top; y <= bottom; y += spacing {
+				r.Rect(x-size/2, y-size/2, size, size)
+			}
+		}
+		r.SetFillStyle(backgroundColor)
+		r.Fill()
+	case backgroundLines:
+		for x := left; x <= right; x += spacing {
+			r.MoveTo(x, area.Y)
+			r.LineTo(x, bottom)
+		}
+		for y := top; y <= bottom; y += spacing {
+			r.MoveTo(area.X, y)
+			r.LineTo(right, y)
+		}
+		r.SetStrokeStyle(backgroundColor)
+		r.SetLineWidth(cm.view.screenLength(1))
+		r.Stroke()
+	}
+}
+
+// drawMinimap 在右下角畫出所有內容與目前畫面的相對位置（螢幕座標），
+// 內容都在畫面中時不顯示
+func (cm *CanvasManager) drawMinimap() {
+	if !cm.showMinimap {
+		return
+	}
+	shapes := layer.VisibleShapes(cm.layers)
+	if len(shapes) == 0 {
+		return
+	}
+	view := cm.visibleArea()
+	content := shape.UnionBounds(shapes)
+	if view.Encloses(content) {
+		return
+	}
+
+	// 將內容與畫面一起縮放到小地圖中並置中
+	world := content.Union(view)
+	scale := math.Min(minimapWidth/math.Max(world.Width, 1), minimapHeight/math.Max(world.Height, 1))
+	box := cm.minimapBox()
+	offsetX := box.X + (minimapWidth-world.Width*scale)/2 - world.X*scale
+	offsetY := box.Y + (minimapHeight-world.Height*scale)/2 - world.Y*scale
+	toMap := func(b shape.Bounds) shape.Bounds {
+		return shape.Bounds{
+			X:      b.X*scale + offsetX,
+			Y:      b.Y*scale + offsetY,
+			Width:  math.Max(b.Width*scale, 1),
+			Height: math.Max(b.Height*scale, 1),
+		}
+	}
+
+	r := cm.renderer
+	r.SetFillStyle("rgba(255, 255, 255, 0.85)")
+	r.SetStrokeStyle("#cccccc")
+	r.SetLineWidth(1)
+	r.BeginPath()
+	r.Rect(box.X, box.Y, box.Width, box.Height)
+	r.Fill()
+	r.Stroke()
+
+	r.SetFillStyle("#999999")
+	r.BeginPath()
+	for _, s := range shapes {
+		b := toMap(s.GetBounds())
+		r.Rect(b.X, b.Y, b.Width, b.Height)
+	}
+	r.Fill()
+
+	v := toMap(view)
+	r.SetStrokeStyle("#4a90d9")
+	r.BeginPath()
+	r.Rect(v.X, v.Y, v.Width, v.Height)
+	r.Stroke()
+}
+
+// minimapBox 回傳小地圖在畫面右下角的範圍（螢幕座標）
+func (cm *CanvasManager) minimapBox() shape.Bounds {
+	return shape.Bounds{
+		X:      cm.width - minimapWidth - minimapMargin,
+		Y:      cm.height - minimapHeight - minimapMargin,
+		Width:  minimapWidth,
+		Height: minimapHeight,
+	}
+}
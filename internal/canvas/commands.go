@@ -0,0 +1,454 @@
+//go:build js && wasm
+
+package canvas
+
+import (
+	"canvas-demo/internal/canvas/layer"
+	"canvas-demo/internal/canvas/shape"
+)
+
+// addShapeCommand 新增形狀（筆畫、文字）到指定圖層
+type addShapeCommand struct {
+	cm    *CanvasManager
+	layer *layer.Layer
+	shape shape.Shape
+	index int
+}
+
+func (c *addShapeCommand) Do() {
+	c.cm.insertShape(c.layer, c.index, c.shape)
+}
+
+func (c *addShapeCommand) Undo() {
+	c.cm.removeShape(c.shape)
+}
+
+// addShapesCommand 將一組形狀依序加到圖層的指定位置（貼上、再製）
+type addShapesCommand struct {
+	cm     *CanvasManager
+	layer  *layer.Layer
+	shapes []shape.Shape
+	index  int
+}
+
+func (c *addShapesCommand) Do() {
+	for i, s := range c.shapes {
+		c.cm.insertShape(c.layer, c.index+i, s)
+	}
+}
+
+func (c *addShapesCommand) Undo() {
+	for _, s := range c.shapes {
+		c.cm.removeShape(s)
+	}
+}
+
+// deleteShapesCommand 刪除一組形狀
+type deleteShapesCommand struct {
+	cm      *CanvasManager
+	shapes  []shape.Shape
+	layers  []*layer.Layer
+	indices []int
+}
+
+func (c *deleteShapesCommand) Do() {
+	c.layers = make([]*layer.Layer, len(c.shapes))
+	c.indices = make([]int, len(c.shapes))
+	for i, s := range c.shapes {
+		c.layers[i], c.indices[i] = c.cm.removeShape(s)
+	}
+}
+
+func (c *deleteShapesCommand) Undo() {
+	// 以相反順序放回原本的位置
+	for i := len(c.shapes) - 1; i >= 0; i-- {
+		c.cm.insertShape(c.layers[i], c.indices[i], c.shapes[i])
+	}
+}
+
+// reshaper 由會改變形狀位置或大小的命令實作，回傳受影響的形狀，用來更新空間索引
+type reshaper interface {
+	reshaped() []shape.Shape
+}
+
+// moveCommand 移動一組形狀，同一次拖曳中的移動會合併成一筆紀錄
+type moveCommand struct {
+	shapes  []shape.Shape
+	dx, dy  float64
+	gesture int
+}
+
+func (c *moveCommand) Do() {
+	for _, s := range c.shapes {
+		s.Move(c.dx, c.dy)
+	}
+}
+
+func (c *moveCommand) Undo() {
+	for _, s := range c.shapes {
+		s.Move(-c.dx, -c.dy)
+	}
+}
+
+func (c *moveCommand) reshaped() []shape.Shape {
+	return c.shapes
+}
+
+func (c *moveCommand) Merge(next Command) bool {
+	n, ok := next.(*moveCommand)
+	if !ok || n.gesture != c.gesture || !sameShapes(c.shapes, n.shapes) {
+		return false
+	}
+	c.dx += n.dx
+	c.dy += n.dy
+	return true
+}
+
+// arrangeCommand 將每個形狀各自移動不同的距離，用於對齊與平均分配
+type arrangeCommand struct {
+	shapes  []shape.Shape
+	offsets []shape.Point
+}
+
+func (c *arrangeCommand) Do() {
+	for i, s := range c.shapes {
+		s.Move(c.offsets[i].X, c.offsets[i].Y)
+	}
+}
+
+func (c *arrangeCommand) Undo() {
+	for i, s := range c.shapes {
+		s.Move(-c.offsets[i].X, -c.offsets[i].Y)
+	}
+}
+
+func (c *arrangeCommand) reshaped() []shape.Shape {
+	return c.shapes
+}
+
+// scaleCommand 以固定中心點縮放一組形狀，同一次拖曳中的縮放會合併成一筆紀錄
+type scaleCommand struct {
+	shapes  []shape.Shape
+	sx, sy  float64
+	center  shape.Point
+	gesture int
+}
+
+func (c *scaleCommand) Do() {
+	for _, s := range c.shapes {
+		s.Scale(c.sx, c.sy, c.center)
+	}
+}
+
+func (c *scaleCommand) Undo() {
+	for _, s := range c.shapes {
+		s.Scale(1/c.sx, 1/c.sy, c.center)
+	}
+}
+
+func (c *scaleCommand) reshaped() []shape.Shape {
+	return c.shapes
+}
+
+func (c *scaleCommand) Merge(next Command) bool {
+	n, ok := next.(*scaleCommand)
+	if !ok || n.gesture != c.gesture || n.center != c.center || !sameShapes(c.shapes, n.shapes) {
+		return false
+	}
+	c.sx *= n.sx
+	c.sy *= n.sy
+	return true
+}
+
+// rotateCommand 以 pivot 為中心旋轉一組形狀，同一次拖曳中的旋轉會合併成一筆紀錄
+type rotateCommand struct {
+	shapes  []shape.Shape
+	angle   float64
+	pivot   shape.Point
+	gesture int
+}
+
+func (c *rotateCommand) Do() {
+	for _, s := range c.shapes {
+		s.Rotate(c.angle, c.pivot)
+	}
+}
+
+func (c *rotateCommand) Undo() {
+	for _, s := range c.shapes {
+		s.Rotate(-c.angle, c.pivot)
+	}
+}
+
+func (c *rotateCommand) reshaped() []shape.Shape {
+	return c.shapes
+}
+
+func (c *rotateCommand) Merge(next Command) bool {
+	n, ok := next.(*rotateCommand)
+	if !ok || n.gesture != c.gesture || n.pivot != c.pivot || !sameShapes(c.shapes, n.shapes) {
+		return false
+	}
+	c.angle += n.angle
+	return true
+}
+
+// endpointCommand 移動直線的其中一個端點，同一次拖曳會合併成一筆紀錄
+type endpointCommand struct {
+	conn     *shape.Connector
+	which    shape.ControlPoint
+	from, to shape.Point
+	gesture  int
+}
+
+func (c *endpointCommand) Do() {
+	c.conn.SetEndpoint(c.which, c.to)
+}
+
+func (c *endpointCommand) Undo() {
+	c.conn.SetEndpoint(c.which, c.from)
+}
+
+func (c *endpointCommand) reshaped() []shape.Shape {
+	return []shape.Shape{c.conn}
+}
+
+func (c *endpointCommand) Merge(next Command) bool {
+	n, ok := next.(*endpointCommand)
+	if !ok || n.gesture != c.gesture || n.conn != c.conn || n.which != c.which {
+		return false
+	}
+	c.to = n.to
+	return true
+}
+
+// editTextCommand 修改文字內容
+type editTextCommand struct {
+	text   *shape.Text
+	before string
+	after  string
+}
+
+func (c *editTextCommand) Do() {
+	c.text.Content = c.after
+}
+
+func (c *editTextCommand) Undo() {
+	c.text.Content = c.before
+}
+
+func (c *editTextCommand) reshaped() []shape.Shape {
+	return []shape.Shape{c.text}
+}
+
+// groupCommand 將一組形狀組成群組，群組放在原本最上層的形狀的圖層與位置
+type groupCommand struct {
+	cm      *CanvasManager
+	group   *shape.Group
+	layers  []*layer.Layer // 子形狀原本所在的圖層
+	indices []int          // 子形狀原本在圖層中的位置
+}
+
+func (c *groupCommand) Do() {
+	n := len(c.group.Children)
+	c.layers = make([]*layer.Layer, n)
+	c.indices = make([]int, n)
+	for i, s := range c.group.Children {
+		c.layers[i], c.indices[i] = c.cm.layerOf(s)
+	}
+
+	// 同一個圖層中位於最上層子形狀之下的子形狀被移除後，群組的位置要往下調整
+	top, index := c.layers[n-1], c.indices[n-1]
+	for _, l := range c.layers[:n-1] {
+		if l == top {
+			index--
+		}
+	}
+
+	for _, s := range c.group.Children {
+		c.cm.removeShape(s)
+	}
+	c.cm.insertShape(top, index, c.group)
+}
+
+func (c *groupCommand) Undo() {
+	c.cm.removeShape(c.group)
+	// 子形狀依由下往上的順序放回，位置就會與原本相同
+	for i, s := range c.group.Children {
+		c.cm.insertShape(c.layers[i], c.indices[i], s)
+	}
+}
+
+// ungroupCommand 將群組拆開，子形狀放回群組原本的位置
+type ungroupCommand struct {
+	cm      *CanvasManager
+	groups  []*shape.Group
+	layers  []*layer.Layer
+	indices []int
+}
+
+func (c *ungroupCommand) Do() {
+	c.layers = make([]*layer.Layer, len(c.groups))
+	c.indices = make([]int, len(c.groups))
+	for i, g := range c.groups {
+		c.layers[i], c.indices[i] = c.cm.removeShape(g)
+		for j, s := range g.Children {
+			c.cm.insertShape(c.layers[i], c.indices[i]+j, s)
+		}
+	}
+}
+
+func (c *ungroupCommand) Undo() {
+	// 以相反順序把子形狀換回群組
+	for i := len(c.groups) - 1; i >= 0; i-- {
+		for _, s := range c.groups[i].Children {
+			c.cm.removeShape(s)
+		}
+		c.cm.insertShape(c.layers[i], c.indices[i], c.groups[i])
+	}
+}
+
+// reorderCommand 改變圖層中形狀的繪製順序（z-order）
+type reorderCommand struct {
+	layers []*layer.Layer
+	before [][]shape.Shape
+	after  [][]shape.Shape
+}
+
+func (c *reorderCommand) Do() {
+	for i, l := range c.layers {
+		l.Shapes = append([]shape.Shape(nil), c.after[i]...)
+	}
+}
+
+func (c *reorderCommand) Undo() {
+	for i, l := range c.layers {
+		l.Shapes = append([]shape.Shape(nil), c.before[i]...)
+	}
+}
+
+// addLayerCommand 新增圖層並設為作用中的圖層
+type addLayerCommand struct {
+	cm    *CanvasManager
+	layer *layer.Layer
+	index int
+	prev  *layer.Layer // 原本作用中的圖層
+}
+
+func (c *addLayerCommand) Do() {
+	c.prev = c.cm.activeLayer
+	c.cm.insertLayer(c.index, c.layer)
+	c.cm.activeLayer = c.layer
+}
+
+func (c *addLayerCommand) Undo() {
+	c.cm.removeLayer(c.layer)
+	c.cm.activeLayer = c.prev
+}
+
+// removeLayerCommand 刪除圖層與其中所有形狀
+type removeLayerCommand struct {
+	cm    *CanvasManager
+	layer *layer.Layer
+	index int
+	prev  *layer.Layer
+}
+
+func (c *removeLayerCommand) Do() {
+	c.prev = c.cm.activeLayer
+	c.index = c.cm.removeLayer(c.layer)
+	for _, s := range c.layer.Shapes {
+		// 文字物件需要移除 HTML 元素
+		s.Delete()
+	}
+	if c.cm.activeLayer == c.layer {
+		// 改用下方的圖層，刪除的是最下層時改用新的最下層
+		c.cm.activeLayer = c.cm.layers[max(c.index-1, 0)]
+	}
+}
+
+func (c *removeLayerCommand) Undo() {
+	c.cm.insertLayer(c.index, c.layer)
+	c.cm.activeLayer = c.prev
+}
+
+// moveLayerCommand 改變圖層的上下順序
+type moveLayerCommand struct {
+	cm       *CanvasManager
+	layer    *layer.Layer
+	from, to int
+}
+
+func (c *moveLayerCommand) Do() {
+	c.cm.removeLayer(c.layer)
+	c.cm.insertLayer(c.to, c.layer)
+}
+
+func (c *moveLayerCommand) Undo() {
+	c.cm.removeLayer(c.layer)
+	c.cm.insertLayer(c.from, c.layer)
+}
+
+// replaceShapeCommand 以一組形狀取代原本的形狀，放在原本的位置（橡皮擦分割筆畫）。
+// replacement 為空時等同刪除
+type replaceShapeCommand struct {
+	cm          *CanvasManager
+	old         shape.Shape
+	replacement []shape.Shape
+	layer       *layer.Layer
+	index       int
+}
+
+func (c *replaceShapeCommand) Do() {
+	c.layer, c.index = c.cm.removeShape(c.old)
+	for i, s := range c.replacement {
+		c.cm.insertShape(c.layer, c.index+i, s)
+	}
+}
+
+func (c *replaceShapeCommand) Undo() {
+	for _, s := range c.replacement {
+		c.cm.removeShape(s)
+	}
+	c.cm.insertShape(c.layer, c.index, c.old)
+}
+
+// eraseCommand 記錄一次橡皮擦拖曳中的所有修改，同一次拖曳會合併成一筆紀錄
+type eraseCommand struct {
+	steps   []Command
+	gesture int
+}
+
+func (c *eraseCommand) Do() {
+	for _, step := range c.steps {
+		step.Do()
+	}
+}
+
+func (c *eraseCommand) Undo() {
+	for i := len(c.steps) - 1; i >= 0; i-- {
+		c.steps[i].Undo()
+	}
+}
+
+func (c *eraseCommand) Merge(next Command) bool {
+	n, ok := next.(*eraseCommand)
+	if !ok || n.gesture != c.gesture {
+		return false
+	}
+	c.steps = append(c.steps, n.steps...)
+	return true
+}
+
+// sameShapes 檢查兩組形狀是否完全相同
+func sameShapes(a, b []shape.Shape) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
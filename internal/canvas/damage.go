@@ -0,0 +1,194 @@
+//go:build js && wasm
+
+package canvas
+
+import (
+	"math"
+
+	"canvas-demo/internal/canvas/shape"
+)
+
+// maxDamageRatio 需要重繪的範圍超過畫面的這個比例時直接重繪整個畫面
+const maxDamageRatio = 0.5
+
+// invalidate 記錄需要重繪的範圍（畫布座標），下次 repaint 時重畫
+func (cm *CanvasManager) invalidate(b shape.Bounds) {
+	cm.damage = append(cm.damage, b)
+}
+
+// overlayBounds 回傳畫在形狀之上的內容所涵蓋的範圍（畫布座標）：
+// 控制點、框選範圍、參考線、橡皮擦與正在拖曳建立的形狀
+func (cm *CanvasManager) overlayBounds() []shape.Bounds {
+	var regions []shape.Bounds
+	if len(cm.selection) > 0 {
+		regions = append(regions, shape.ControlsBounds(cm.selectionBounds(), cm.view.scale))
+	}
+	if cm.isSelecting {
+		regions = append(regions, cm.marquee())
+	}
+	tick := cm.view.screenLength(guideTickSize)
+	for _, g := range cm.guides {
+		regions = append(regions, shape.Bounds{
+			X:      math.Min(g.from.X, g.to.X),
+			Y:      math.Min(g.from.Y, g.to.Y),
+			Width:  math.Abs(g.to.X - g.from.X),
+			Height: math.Abs(g.to.Y - g.from.Y),
+		}.Inflate(tick))
+	}
+	if cm.currentTool == "eraser" && cm.hovering {
+		regions = append(regions, shape.Bounds{
+			X:      cm.hover.X - cm.eraserRadius,
+			Y:      cm.hover.Y - cm.eraserRadius,
+			Width:  cm.eraserRadius * 2,
+			Height: cm.eraserRadius * 2,
+		})
+	}
+	if cm.currentBox != nil {
+		regions = append(regions, cm.currentBox.GetBounds())
+	}
+	if cm.currentConn != nil {
+		regions = append(regions, cm.currentConn.GetBounds())
+	}
+	return regions
+}
+
+// repaint 標記需要重繪變更的範圍，在下一個影格繪製
+func (cm *CanvasManager) repaint() {
+	cm.frame.repaint = true
+	cm.requestFrame()
+}
+
+// drawDamage 只重繪上次繪製後變更的範圍：invalidate 記錄的範圍，以及覆蓋內容的舊位置與新位置。
+// 只有與這些範圍重疊的形狀會重畫，並裁剪在範圍內；範圍太大時改為重繪整個畫面
+func (cm *CanvasManager) drawDamage() {
+	overlay := cm.overlayBounds()
+	regions := append(append(cm.damage, cm.overlay...), overlay...)
+	cm.damage, cm.overlay = nil, overlay
+
+	// 換算成螢幕上的整數像素範圍，多保留的範圍涵蓋線寬與反鋸齒
+	screen := shape.Bounds{Width: cm.width, Height: cm.height}
+	var rects []shape.Bounds
+	area := 0.0
+	for _, b := range regions {
+		s, ok := clampBounds(cm.toScreenRect(b.Inflate(cullMargin)), screen)
+		if !ok {
+			continue
+		}
+		rects = append(rects, s)
+		area += s.Width * s.Height
+	}
+	if len(rects) == 0 {
+		return
+	}
+	if area > maxDamageRatio*cm.width*cm.height {
+		cm.drawAll()
+		return
+	}
+
+	// 小地圖蓋在形狀之上，範圍碰到小地圖時整個小地圖一起重繪
+	minimap := cm.minimapBox()
+	withMinimap := false
+	for _, s := range rects {
+		if cm.showMinimap && s.Intersects(minimap) {
+			withMinimap = true
+			break
+		}
+	}
+	if withMinimap {
+		rects = append(rects, minimap)
+	}
+
+	r := cm.renderer
+	r.Save()
+	r.BeginPath()
+	for _, s := range rects {
+		r.Rect(s.X, s.Y, s.Width, s.Height)
+	}
+	r.Clip()
+	cm.Clear()
+
+	areas := make([]shape.Bounds, len(rects))
+	for i, s := range rects {
+		areas[i] = cm.toWorldRect(s)
+	}
+	cm.drawScene(areas)
+	r.Restore()
+
+	if withMinimap {
+		cm.drawMinimap()
+	}
+}
+
+// toScreenRect 將畫布座標的範圍換算成涵蓋它的螢幕整數像素範圍
+func (cm *CanvasManager) toScreenRect(b shape.Bounds) shape.Bounds {
+	v := cm.view
+	left := math.Floor(b.X*v.scale + v.x)
+	top := math.Floor(b.Y*v.scale + v.y)
+	right := math.Ceil((b.X+b.Width)*v.scale + v.x)
+	bottom := math.Ceil((b.Y+b.Height)*v.scale + v.y)
+	return shape.Bounds{X: left, Y: top, Width: right - left, Height: bottom - top}
+}
+
+// toWorldRect 將螢幕範圍換算成畫布座標
+func (cm *CanvasManager) toWorldRect(s shape.Bounds) shape.Bounds {
+	topLeft := cm.view.toWorld(shape.Point{X: s.X, Y: s.Y})
+	return shape.Bounds{
+		X:      topLeft.X,
+		Y:      topLeft.Y,
+		Width:  cm.view.screenLength(s.Width),
+		Height: cm.view.screenLength(s.Height),
+	}
+}
+
+// clampBounds 回傳 b 在 limit 內的部分，沒有重疊時回傳 false
+func clampBounds(b, limit shape.Bounds) (shape.Bounds, bool) {
+	left := math.Max(b.X, limit.X)
+	top := math.Max(b.Y, limit.Y)
+	right := math.Min(b.X+b.Width, limit.X+limit.Width)
+	bottom := math.Min(b.Y+b.Height, limit.Y+limit.Height)
+	if right <= left || bottom <= top {
+		return shape.Bounds{}, false
+	}
+	return shape.Bounds{X: left, Y: top, Width: right - left, Height: bottom - top}, true
+}
+
+// paintStroke 標記繪製中的筆畫有新增的點，在下一個影格畫出
+func (cm *CanvasManager) paintStroke() {
+	cm.frame.stroke = true
+	cm.requestFrame()
+}
+
+// drawStroke 只畫出繪製中的筆畫上次繪製後新增的線段，不清除也不重畫其他內容。
+// 新線段碰到小地圖時改為局部重繪，避免畫在小地圖上
+func (cm *CanvasManager) drawStroke() {
+	l := cm.currentLine
+	if l == nil {
+		return
+	}
+	from := max(cm.strokePainted-1, 0)
+	if from >= len(l.Points)-1 {
+		return
+	}
+	cm.strokePainted = len(l.Points)
+
+	first := l.Points[from]
+	segment := shape.Bounds{X: first.X, Y: first.Y}
+	for _, p := range l.Points[from+1:] {
+		segment = segment.Union(shape.Bounds{X: p.X, Y: p.Y})
+	}
+	if cm.showMinimap {
+		s := cm.toScreenRect(segment.Inflate(l.Style.LineWidth))
+		if s.Intersects(cm.minimapBox()) {
+			cm.invalidate(segment)
+			cm.drawDamage()
+			return
+		}
+	}
+
+	r := cm.renderer
+	r.Save()
+	r.Translate(cm.view.x, cm.view.y)
+	r.Scale(cm.view.scale, cm.view.scale)
+	l.DrawSegments(r, from)
+	r.Restore()
+}
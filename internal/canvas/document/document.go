@@ -0,0 +1,569 @@
+// Package document 定義畫布內容的 JSON 文件格式，負責形狀的儲存與載入。
+package document
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"canvas-demo/internal/canvas/grid"
+	"canvas-demo/internal/canvas/layer"
+	"canvas-demo/internal/canvas/shape"
+)
+
+// Version 目前的文件格式版本。版本 1 只有一個形狀列表，版本 2 加入圖層
+const Version = 2
+
+// Document 表示一份可儲存的畫布內容
+type Document struct {
+	Layers []*layer.Layer // 由下往上的順序
+	Grid   *grid.Grid     // 格線設定，nil 表示文件中沒有儲存
+}
+
+// file 是文件在 JSON 中的最外層結構
+type file struct {
+	Version int               `json:"version"`
+	Layers  []layerRecord     `json:"layers,omitempty"`
+	Grid    *gridRecord       `json:"grid,omitempty"`
+	Shapes  []json.RawMessage `json:"shapes,omitempty"` // 版本 1
+}
+
+// gridRecord 是 grid.Grid 的 JSON 表示
+type gridRecord struct {
+	Spacing      float64 `json:"spacing"`
+	Subdivisions int     `json:"subdivisions"`
+	Color        string  `json:"color"`
+	Visible      bool    `json:"visible,omitempty"`
+	Snap         bool    `json:"snap,omitempty"`
+}
+
+// layerRecord 是 layer.Layer 的 JSON 表示
+type layerRecord struct {
+	Name    string            `json:"name"`
+	Visible *bool             `json:"visible"`
+	Locked  bool              `json:"locked,omitempty"`
+	Opacity *float64          `json:"opacity"`
+	Shapes  []json.RawMessage `json:"shapes"`
+}
+
+// point 是座標點的 JSON 表示
+type point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// header 用來先讀出形狀的類型
+type header struct {
+	Type string `json:"type"`
+}
+
+// lineRecord 是 shape.Line 的 JSON 表示
+type lineRecord struct {
+	Type      string     `json:"type"`
+	Points    []point    `json:"points"`
+	Rotation  float64    `json:"rotation,omitempty"`
+	Smoothing string     `json:"smoothing,omitempty"` // 省略時以直線段連接
+	Pressures []float64  `json:"pressures,omitempty"` // 每個點的筆壓，省略時為固定寬度
+	Pen       *penRecord `json:"pen,omitempty"`       // 有筆壓時必須提供
+	Style     struct {
+		StrokeStyle string  `json:"strokeStyle"`
+		LineWidth   float64 `json:"lineWidth"`
+	} `json:"style"`
+}
+
+// penRecord 是 shape.PenStyle 的 JSON 表示
+type penRecord struct {
+	MinWidth   float64 `json:"minWidth"`
+	MaxWidth   float64 `json:"maxWidth"`
+	Thinning   float64 `json:"thinning"`
+	TaperStart float64 `json:"taperStart,omitempty"`
+	TaperEnd   float64 `json:"taperEnd,omitempty"`
+}
+
+// textRecord 是 shape.Text 的 JSON 表示
+type textRecord struct {
+	Type     string  `json:"type"`
+	Content  string  `json:"content"`
+	Position point   `json:"position"`
+	Rotation float64 `json:"rotation,omitempty"`
+	Style    struct {
+		Font      string  `json:"font"`
+		FillStyle string  `json:"fillStyle"`
+		Size      float64 `json:"size"`
+	} `json:"style"`
+}
+
+// boxRecord 是 shape.Rect 與 shape.Ellipse 的 JSON 表示
+type boxRecord struct {
+	Type     string  `json:"type"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	Width    float64 `json:"width"`
+	Height   float64 `json:"height"`
+	Rotation float64 `json:"rotation,omitempty"`
+	Style    struct {
+		StrokeStyle string  `json:"strokeStyle,omitempty"`
+		LineWidth   float64 `json:"lineWidth,omitempty"`
+		FillStyle   string  `json:"fillStyle,omitempty"`
+	} `json:"style"`
+}
+
+// connectorRecord 是 shape.Connector 的 JSON 表示
+type connectorRecord struct {
+	Type        string `json:"type"`
+	Start       point  `json:"start"`
+	End         point  `json:"end"`
+	StartMarker string `json:"startMarker,omitempty"`
+	EndMarker   string `json:"endMarker,omitempty"`
+	Style       struct {
+		StrokeStyle string  `json:"strokeStyle"`
+		LineWidth   float64 `json:"lineWidth"`
+	} `json:"style"`
+}
+
+// groupRecord 是 shape.Group 的 JSON 表示，子形狀使用相同的格式
+type groupRecord struct {
+	Type     string            `json:"type"`
+	Children []json.RawMessage `json:"children"`
+}
+
+const (
+	typeGroup     = "group"
+	typeLine      = "line"
+	typeConnector = "connector"
+	typeText      = "text"
+	typeRect      = "rect"
+	typeEllipse   = "ellipse"
+)
+
+// Marshal 將文件序列化為 JSON
+func Marshal(doc *Document) ([]byte, error) {
+	f := file{
+		Version: Version,
+		Layers:  make([]layerRecord, 0, len(doc.Layers)),
+	}
+
+	for i, l := range doc.Layers {
+		visible, opacity := l.Visible, l.Opacity
+		rec := layerRecord{
+			Name:    l.Name,
+			Visible: &visible,
+			Locked:  l.Locked,
+			Opacity: &opacity,
+			Shapes:  make([]json.RawMessage, 0, len(l.Shapes)),
+		}
+		for j, s := range l.Shapes {
+			raw, err := encodeShape(s)
+			if err != nil {
+				return nil, fmt.Errorf("document: layers[%d]: shapes[%d]: %w", i, j, err)
+			}
+			rec.Shapes = append(rec.Shapes, raw)
+		}
+		f.Layers = append(f.Layers, rec)
+	}
+
+	if g := doc.Grid; g != nil {
+		f.Grid = &gridRecord{
+			Spacing:      g.Spacing,
+			Subdivisions: g.Subdivisions,
+			Color:        g.Color,
+			Visible:      g.Visible,
+			Snap:         g.Snap,
+		}
+	}
+
+	return json.Marshal(f)
+}
+
+// Unmarshal 解析 JSON 文件並重建所有形狀，格式不正確時回傳描述錯誤的 error。
+// 版本 1 的文件會被讀成只有一個圖層
+func Unmarshal(data []byte) (*Document, error) {
+	var f file
+	if err := decodeStrict(data, &f); err != nil {
+		return nil, fmt.Errorf("document: invalid JSON: %w", err)
+	}
+
+	if f.Version == 0 {
+		return nil, errors.New("document: missing version")
+	}
+	if f.Version < 0 || f.Version > Version {
+		return nil, fmt.Errorf("document: unsupported version %d (latest is %d)", f.Version, Version)
+	}
+
+	if f.Version == 1 {
+		if f.Shapes == nil {
+			return nil, errors.New(`document: missing "shapes"`)
+		}
+		if f.Layers != nil {
+			return nil, errors.New(`document: "layers" requires version 2`)
+		}
+		if f.Grid != nil {
+			return nil, errors.New(`document: "grid" requires version 2`)
+		}
+		shapes, err := decodeShapes(f.Shapes)
+		if err != nil {
+			return nil, fmt.Errorf("document: %w", err)
+		}
+		l := layer.New(1, layer.DefaultName(1))
+		l.Shapes = shapes
+		return &Document{Layers: []*layer.Layer{l}}, nil
+	}
+
+	if f.Shapes != nil {
+		return nil, errors.New(`document: "shapes" must be inside "layers" since version 2`)
+	}
+	if len(f.Layers) == 0 {
+		return nil, errors.New(`document: missing "layers"`)
+	}
+
+	doc := &Document{Layers: make([]*layer.Layer, 0, len(f.Layers))}
+	for i := range f.Layers {
+		l, err := f.Layers[i].toLayer(i + 1)
+		if err != nil {
+			return nil, fmt.Errorf("document: layers[%d]: %w", i, err)
+		}
+		doc.Layers = append(doc.Layers, l)
+	}
+
+	if f.Grid != nil {
+		g := grid.Grid{
+			Spacing:      f.Grid.Spacing,
+			Subdivisions: f.Grid.Subdivisions,
+			Color:        f.Grid.Color,
+			Visible:      f.Grid.Visible,
+			Snap:         f.Grid.Snap,
+		}
+		if err := g.Validate(); err != nil {
+			return nil, fmt.Errorf("document: grid: %w", err)
+		}
+		doc.Grid = &g
+	}
+
+	return doc, nil
+}
+
+func (rec *layerRecord) toLayer(id int) (*layer.Layer, error) {
+	if rec.Visible == nil {
+		return nil, errors.New(`missing "visible"`)
+	}
+	if rec.Opacity == nil {
+		return nil, errors.New(`missing "opacity"`)
+	}
+	if *rec.Opacity < 0 || *rec.Opacity > 1 {
+		return nil, fmt.Errorf("invalid opacity %g", *rec.Opacity)
+	}
+	if rec.Shapes == nil {
+		return nil, errors.New(`missing "shapes"`)
+	}
+
+	shapes, err := decodeShapes(rec.Shapes)
+	if err != nil {
+		return nil, err
+	}
+
+	l := layer.New(id, rec.Name)
+	l.Visible = *rec.Visible
+	l.Locked = rec.Locked
+	l.Opacity = *rec.Opacity
+	l.Shapes = shapes
+	return l, nil
+}
+
+// decodeShapes 依序解析形狀列表
+func decodeShapes(raws []json.RawMessage) ([]shape.Shape, error) {
+	shapes := make([]shape.Shape, 0, len(raws))
+	for i, raw := range raws {
+		s, err := decodeShape(raw)
+		if err != nil {
+			return nil, fmt.Errorf("shapes[%d]: %w", i, err)
+		}
+		shapes = append(shapes, s)
+	}
+	return shapes, nil
+}
+
+// encodeShape 將單一形狀轉為 JSON
+func encodeShape(s shape.Shape) (json.RawMessage, error) {
+	switch v := s.(type) {
+	case *shape.Line:
+		rec := lineRecord{
+			Type:      typeLine,
+			Points:    make([]point, len(v.Points)),
+			Rotation:  v.Rotation,
+			Smoothing: string(v.Smoothing),
+		}
+		for i, p := range v.Points {
+			rec.Points[i] = point{X: p.X, Y: p.Y}
+		}
+		if v.HasPressure() {
+			rec.Pressures = v.Pressures
+			rec.Pen = &penRecord{
+				MinWidth:   v.Pen.MinWidth,
+				MaxWidth:   v.Pen.MaxWidth,
+				Thinning:   v.Pen.Thinning,
+				TaperStart: v.Pen.TaperStart,
+				TaperEnd:   v.Pen.TaperEnd,
+			}
+		}
+		rec.Style.StrokeStyle = v.Style.StrokeStyle
+		rec.Style.LineWidth = v.Style.LineWidth
+		return json.Marshal(rec)
+	case *shape.Text:
+		rec := textRecord{
+			Type:     typeText,
+			Content:  v.Content,
+			Position: point{X: v.Position.X, Y: v.Position.Y},
+			Rotation: v.Rotation,
+		}
+		rec.Style.Font = v.Style.Font
+		rec.Style.FillStyle = v.Style.FillStyle
+		rec.Style.Size = v.Style.Size
+		return json.Marshal(rec)
+	case *shape.Rect:
+		b, angle := v.OrientedBounds()
+		return json.Marshal(newBoxRecord(typeRect, b, angle, v.Style))
+	case *shape.Ellipse:
+		b, angle := v.OrientedBounds()
+		return json.Marshal(newBoxRecord(typeEllipse, b, angle, v.Style))
+	case *shape.Connector:
+		rec := connectorRecord{
+			Type:  typeConnector,
+			Start: point{X: v.Start.X, Y: v.Start.Y},
+			End:   point{X: v.End.X, Y: v.End.Y},
+		}
+		if v.StartMarker != shape.MarkerNone {
+			rec.StartMarker = string(v.StartMarker)
+		}
+		if v.EndMarker != shape.MarkerNone {
+			rec.EndMarker = string(v.EndMarker)
+		}
+		rec.Style.StrokeStyle = v.Style.StrokeStyle
+		rec.Style.LineWidth = v.Style.LineWidth
+		return json.Marshal(rec)
+	case *shape.Group:
+		rec := groupRecord{Type: typeGroup, Children: make([]json.RawMessage, 0, len(v.Children))}
+		for i, c := range v.Children {
+			raw, err := encodeShape(c)
+			if err != nil {
+				return nil, fmt.Errorf("children[%d]: %w", i, err)
+			}
+			rec.Children = append(rec.Children, raw)
+		}
+		return json.Marshal(rec)
+	default:
+		return nil, fmt.Errorf("unsupported shape %T", s)
+	}
+}
+
+// decodeShape 依類型解析單一形狀
+func decodeShape(raw json.RawMessage) (shape.Shape, error) {
+	var h header
+	if err := json.Unmarshal(raw, &h); err != nil {
+		return nil, fmt.Errorf("invalid shape: %w", err)
+	}
+
+	switch h.Type {
+	case typeLine:
+		var rec lineRecord
+		if err := decodeStrict(raw, &rec); err != nil {
+			return nil, fmt.Errorf("invalid line: %w", err)
+		}
+		return rec.toShape()
+	case typeText:
+		var rec textRecord
+		if err := decodeStrict(raw, &rec); err != nil {
+			return nil, fmt.Errorf("invalid text: %w", err)
+		}
+		return rec.toShape()
+	case typeConnector:
+		var rec connectorRecord
+		if err := decodeStrict(raw, &rec); err != nil {
+			return nil, fmt.Errorf("invalid connector: %w", err)
+		}
+		return rec.toShape()
+	case typeRect, typeEllipse:
+		var rec boxRecord
+		if err := decodeStrict(raw, &rec); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", h.Type, err)
+		}
+		return rec.toShape()
+	case typeGroup:
+		var rec groupRecord
+		if err := decodeStrict(raw, &rec); err != nil {
+			return nil, fmt.Errorf("invalid group: %w", err)
+		}
+		return rec.toShape()
+	case "":
+		return nil, errors.New(`missing "type"`)
+	default:
+		return nil, fmt.Errorf("unknown shape type %q", h.Type)
+	}
+}
+
+func (rec *lineRecord) toShape() (shape.Shape, error) {
+	if len(rec.Points) == 0 {
+		return nil, errors.New("line has no points")
+	}
+	if rec.Style.StrokeStyle == "" {
+		return nil, errors.New(`line is missing "style.strokeStyle"`)
+	}
+	if rec.Style.LineWidth <= 0 {
+		return nil, fmt.Errorf("line has invalid width %g", rec.Style.LineWidth)
+	}
+	smoothing, err := shape.ParseSmoothing(rec.Smoothing)
+	if err != nil {
+		return nil, fmt.Errorf("line has %w", err)
+	}
+
+	line := shape.NewLine(shape.Style{
+		StrokeStyle: rec.Style.StrokeStyle,
+		LineWidth:   rec.Style.LineWidth,
+	})
+	for _, p := range rec.Points {
+		line.AddPoint(shape.Point{X: p.X, Y: p.Y})
+	}
+	line.Rotation = rec.Rotation
+	line.Smoothing = smoothing
+
+	if rec.Pressures != nil {
+		if len(rec.Pressures) != len(rec.Points) {
+			return nil, fmt.Errorf("line has %d pressures for %d points", len(rec.Pressures), len(rec.Points))
+		}
+		for _, p := range rec.Pressures {
+			if p < 0 || p > 1 {
+				return nil, fmt.Errorf("line has invalid pressure %g", p)
+			}
+		}
+		if rec.Pen == nil {
+			return nil, errors.New(`line with pressures is missing "pen"`)
+		}
+		line.Pressures = rec.Pressures
+		line.Pen = shape.PenStyle{
+			MinWidth:   rec.Pen.MinWidth,
+			MaxWidth:   rec.Pen.MaxWidth,
+			Thinning:   rec.Pen.Thinning,
+			TaperStart: rec.Pen.TaperStart,
+			TaperEnd:   rec.Pen.TaperEnd,
+		}
+		if err := line.Pen.Validate(); err != nil {
+			return nil, fmt.Errorf("line has %w", err)
+		}
+	}
+	return line, nil
+}
+
+func (rec *textRecord) toShape() (shape.Shape, error) {
+	if rec.Style.Font == "" {
+		return nil, errors.New(`text is missing "style.font"`)
+	}
+	if rec.Style.FillStyle == "" {
+		return nil, errors.New(`text is missing "style.fillStyle"`)
+	}
+	if rec.Style.Size <= 0 {
+		return nil, fmt.Errorf("text has invalid size %g", rec.Style.Size)
+	}
+
+	text := shape.NewText(shape.Point{X: rec.Position.X, Y: rec.Position.Y}, shape.TextStyle{
+		Font:      rec.Style.Font,
+		FillStyle: rec.Style.FillStyle,
+		Size:      rec.Style.Size,
+	})
+	text.Content = rec.Content
+	text.Rotation = rec.Rotation
+	return text, nil
+}
+
+func newBoxRecord(typ string, b shape.Bounds, angle float64, style shape.Style) boxRecord {
+	rec := boxRecord{Type: typ, X: b.X, Y: b.Y, Width: b.Width, Height: b.Height, Rotation: angle}
+	rec.Style.StrokeStyle = style.StrokeStyle
+	rec.Style.LineWidth = style.LineWidth
+	rec.Style.FillStyle = style.FillStyle
+	return rec
+}
+
+func (rec *boxRecord) toShape() (shape.Shape, error) {
+	if rec.Width < 0 || rec.Height < 0 {
+		return nil, fmt.Errorf("%s has negative size %gx%g", rec.Type, rec.Width, rec.Height)
+	}
+	if rec.Style.StrokeStyle == "" && rec.Style.FillStyle == "" {
+		return nil, fmt.Errorf("%s has neither stroke nor fill", rec.Type)
+	}
+	if rec.Style.StrokeStyle != "" && rec.Style.LineWidth <= 0 {
+		return nil, fmt.Errorf("%s has invalid width %g", rec.Type, rec.Style.LineWidth)
+	}
+
+	style := shape.Style{
+		StrokeStyle: rec.Style.StrokeStyle,
+		LineWidth:   rec.Style.LineWidth,
+		FillStyle:   rec.Style.FillStyle,
+	}
+	a := shape.Point{X: rec.X, Y: rec.Y}
+	b := shape.Point{X: rec.X + rec.Width, Y: rec.Y + rec.Height}
+
+	if rec.Type == typeRect {
+		r := shape.NewRect(style)
+		r.SetCorners(a, b)
+		r.Rotation = rec.Rotation
+		return r, nil
+	}
+	e := shape.NewEllipse(style)
+	e.SetCorners(a, b)
+	e.Rotation = rec.Rotation
+	return e, nil
+}
+
+func (rec *connectorRecord) toShape() (shape.Shape, error) {
+	if rec.Style.StrokeStyle == "" {
+		return nil, errors.New(`connector is missing "style.strokeStyle"`)
+	}
+	if rec.Style.LineWidth <= 0 {
+		return nil, fmt.Errorf("connector has invalid width %g", rec.Style.LineWidth)
+	}
+	startMarker, err := shape.ParseMarker(rec.StartMarker)
+	if err != nil {
+		return nil, fmt.Errorf("connector start: %w", err)
+	}
+	endMarker, err := shape.ParseMarker(rec.EndMarker)
+	if err != nil {
+		return nil, fmt.Errorf("connector end: %w", err)
+	}
+
+	c := shape.NewConnector(shape.Style{
+		StrokeStyle: rec.Style.StrokeStyle,
+		LineWidth:   rec.Style.LineWidth,
+	}, startMarker, endMarker)
+	c.SetEndpoints(shape.Point{X: rec.Start.X, Y: rec.Start.Y}, shape.Point{X: rec.End.X, Y: rec.End.Y})
+	return c, nil
+}
+
+func (rec *groupRecord) toShape() (shape.Shape, error) {
+	if len(rec.Children) == 0 {
+		return nil, errors.New("group has no children")
+	}
+
+	children := make([]shape.Shape, 0, len(rec.Children))
+	for i, raw := range rec.Children {
+		s, err := decodeShape(raw)
+		if err != nil {
+			return nil, fmt.Errorf("children[%d]: %w", i, err)
+		}
+		children = append(children, s)
+	}
+	return shape.NewGroup(children), nil
+}
+
+// decodeStrict 解析 JSON 並拒絕未知欄位與多餘的內容
+func decodeStrict(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	// More 對多出來的 } 或 ] 回傳 false，要讀到 EOF 才算結束
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("unexpected data after document")
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package document
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"canvas-demo/internal/canvas/layer"
+	"canvas-demo/internal/canvas/shape"
+)
+
+// testShapes 回傳每種形狀各一個，涵蓋旋轉、平滑、筆壓與巢狀群組
+func testShapes() []shape.Shape {
+	line := shape.NewLine(shape.Style{StrokeStyle: "#ff0000", LineWidth: 3})
+	line.AddPoint(shape.Point{X: 1, Y: 2})
+	line.AddPoint(shape.Point{X: 30, Y: 40})
+	line.Rotation = 0.5
+
+	smooth := shape.NewLine(shape.Style{StrokeStyle: "#000000", LineWidth: 2})
+	smooth.Smoothing = shape.SmoothCatmullRom
+	for _, p := range []shape.Point{{X: 0, Y: 0}, {X: 10, Y: 20}, {X: 20, Y: 0}} {
+		smooth.AddPoint(p)
+	}
+
+	pen := shape.NewLine(shape.Style{StrokeStyle: "#0000ff", LineWidth: 6})
+	pen.Smoothing = shape.SmoothQuadratic
+	pen.Pen = shape.PenStyle{MinWidth: 1, MaxWidth: 6, Thinning: 0.5, TaperStart: 10, TaperEnd: 20}
+	pen.AddPressurePoint(shape.Point{X: 0, Y: 0}, 0.25)
+	pen.AddPressurePoint(shape.Point{X: 50, Y: 10}, 1)
+	pen.AddPressurePoint(shape.Point{X: 100, Y: 0}, 0.5)
+
+	text := shape.NewText(shape.Point{X: 10, Y: 50}, shape.TextStyle{Font: "20px Arial", FillStyle: "#333333", Size: 20})
+	text.Content = "你好"
+	text.Rotation = -1
+
+	rect := shape.NewRect(shape.Style{StrokeStyle: "#000000", LineWidth: 1, FillStyle: "#00ff00"})
+	rect.SetCorners(shape.Point{X: 10, Y: 20}, shape.Point{X: 110, Y: 70})
+	rect.Rotation = 0.25
+
+	ellipse := shape.NewEllipse(shape.Style{FillStyle: "#ffff00"})
+	ellipse.SetCorners(shape.Point{X: -20, Y: -10}, shape.Point{X: 20, Y: 10})
+
+	connector := shape.NewConnector(shape.Style{StrokeStyle: "#000000", LineWidth: 2}, shape.MarkerDot, shape.MarkerArrow)
+	connector.SetEndpoints(shape.Point{X: 5, Y: 5}, shape.Point{X: 200, Y: 100})
+
+	plain := shape.NewConnector(shape.Style{StrokeStyle: "#000000", LineWidth: 1}, shape.MarkerNone, shape.MarkerNone)
+	plain.SetEndpoints(shape.Point{X: 0, Y: 0}, shape.Point{X: 10, Y: 0})
+
+	group := shape.NewGroup([]shape.Shape{
+		rect.Clone(),
+		shape.NewGroup([]shape.Shape{line.Clone(), text.Clone()}),
+	})
+
+	return []shape.Shape{line, smooth, pen, text, rect, ellipse, connector, plain, group}
+}
+
+func TestRoundTrip(t *testing.T) {
+	l := layer.New(1, layer.DefaultName(1))
+	l.Shapes = testShapes()
+
+	data, err := Marshal(&Document{Layers: []*layer.Layer{l}})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	doc, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v\n%s", err, data)
+	}
+	if len(doc.Layers) != 1 {
+		t.Fatalf("got %d layers, want 1", len(doc.Layers))
+	}
+
+	got := doc.Layers[0].Shapes
+	if len(got) != len(l.Shapes) {
+		t.Fatalf("got %d shapes, want %d", len(got), len(l.Shapes))
+	}
+	for i := range got {
+		if !reflect.DeepEqual(got[i], l.Shapes[i]) {
+			t.Errorf("shapes[%d] = %+v, want %+v", i, got[i], l.Shapes[i])
+		}
+	}
+}
+
+// shapesDoc 回傳只有一個圖層、包含 shapes 的版本 2 文件
+func shapesDoc(shapes string) string {
+	return `{"version":2,"layers":[{"name":"圖層 1","visible":true,"opacity":1,"shapes":[` + shapes + `]}]}`
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	const (
+		line  = `{"type":"line","points":[{"x":0,"y":0},{"x":1,"y":1}],"style":{"strokeStyle":"#000000","lineWidth":1}}`
+		style = `"style":{"strokeStyle":"#000000","lineWidth":1}`
+	)
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"not JSON", `{"version":`, "invalid JSON"},
+		{"missing version", `{"layers":[]}`, "missing version"},
+		{"future version", `{"version":3,"layers":[]}`, "unsupported version 3"},
+		{"negative version", `{"version":-1,"layers":[]}`, "unsupported version -1"},
+		{"unknown document field", `{"version":2,"layers":[],"extra":1}`, `unknown field "extra"`},
+		{"unknown shape field", shapesDoc(`{"type":"line","points":[{"x":0,"y":0}],"color":"red",` + style + `}`), `unknown field "color"`},
+		{"missing type", shapesDoc(`{"points":[]}`), `shapes[0]: missing "type"`},
+		{"unknown type", shapesDoc(`{"type":"star"}`), `unknown shape type "star"`},
+		{"empty points", shapesDoc(`{"type":"line","points":[],` + style + `}`), "line has no points"},
+		{"zero width", shapesDoc(`{"type":"line","points":[{"x":0,"y":0}],"style":{"strokeStyle":"#000000","lineWidth":0}}`), "line has invalid width 0"},
+		{"negative width", shapesDoc(`{"type":"connector","start":{"x":0,"y":0},"end":{"x":1,"y":1},"style":{"strokeStyle":"#000000","lineWidth":-2}}`), "connector has invalid width -2"},
+		{"unknown smoothing", shapesDoc(`{"type":"line","points":[{"x":0,"y":0}],"smoothing":"bezier",` + style + `}`), `unknown smoothing "bezier"`},
+		{"pressure count", shapesDoc(`{"type":"line","points":[{"x":0,"y":0}],"pressures":[0.5,0.5],` + style + `}`), "1 points"},
+		{"pressures without pen", shapesDoc(`{"type":"line","points":[{"x":0,"y":0}],"pressures":[0.5],` + style + `}`), `missing "pen"`},
+		{"unknown marker", shapesDoc(`{"type":"connector","start":{"x":0,"y":0},"end":{"x":1,"y":1},"endMarker":"star",` + style + `}`), `unknown marker "star"`},
+		{"negative size", shapesDoc(`{"type":"rect","x":0,"y":0,"width":-1,"height":5,` + style + `}`), "rect has negative size"},
+		{"no paint", shapesDoc(`{"type":"ellipse","x":0,"y":0,"width":1,"height":5,"style":{}}`), "ellipse has neither stroke nor fill"},
+		{"empty group", shapesDoc(`{"type":"group","children":[]}`), "group has no children"},
+		{"bad group child", shapesDoc(`{"type":"group","children":[` + line + `,{"type":"star"}]}`), `shapes[0]: children[1]: unknown shape type "star"`},
+		{"trailing object", shapesDoc(line) + `{}`, "unexpected data after document"},
+		{"trailing brace", shapesDoc(line) + `}`, "unexpected data after document"},
+		{"trailing bracket", shapesDoc(line) + `]`, "unexpected data after document"},
+	}
+	for _, tt := range tests {
+		doc, err := Unmarshal([]byte(tt.data))
+		if err == nil {
+			t.Errorf("%s: Unmarshal succeeded with %d layers, want error containing %q", tt.name, len(doc.Layers), tt.want)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: Unmarshal error = %q, want it to contain %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalAllowsTrailingWhitespace(t *testing.T) {
+	if _, err := Unmarshal([]byte(shapesDoc("") + "\n\t ")); err != nil {
+		t.Errorf("Unmarshal: %v", err)
+	}
+}
+
+func TestMigrateVersion1(t *testing.T) {
+	const line = `{"type":"line","points":[{"x":0,"y":0},{"x":1,"y":1}],"style":{"strokeStyle":"#000000","lineWidth":1}}`
+	doc, err := Unmarshal([]byte(`{"version":1,"shapes":[` + line + `,` + line + `]}`))
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(doc.Layers) != 1 {
+		t.Fatalf("got %d layers, want 1", len(doc.Layers))
+	}
+	l := doc.Layers[0]
+	if l.ID != 1 || l.Name != layer.DefaultName(1) || !l.Visible || l.Locked || l.Opacity != 1 {
+		t.Errorf("layer = %+v, want a visible, unlocked, opaque %q", l, layer.DefaultName(1))
+	}
+	if len(l.Shapes) != 2 {
+		t.Errorf("got %d shapes, want 2", len(l.Shapes))
+	}
+	if doc.Grid != nil {
+		t.Errorf("Grid = %+v, want nil", doc.Grid)
+	}
+
+	// 再次儲存時寫成目前的版本
+	data, err := Marshal(doc)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if !strings.HasPrefix(string(data), `{"version":2,"layers":[`) {
+		t.Errorf("Marshal = %s, want a version 2 document", data)
+	}
+}
+
+func TestLayersRoundTrip(t *testing.T) {
+	shapes := testShapes()
+	bottom := layer.New(1, "底圖")
+	bottom.Locked = true
+	bottom.Shapes = shapes[:2]
+	top := layer.New(2, "")
+	top.Visible = false
+	top.Opacity = 0.25
+	top.Shapes = shapes[2:]
+
+	data, err := Marshal(&Document{Layers: []*layer.Layer{bottom, top, layer.New(3, "empty")}})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	doc, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v\n%s", err, data)
+	}
+	if len(doc.Layers) != 3 {
+		t.Fatalf("got %d layers, want 3", len(doc.Layers))
+	}
+	for i, want := range []*layer.Layer{bottom, top, layer.New(3, "empty")} {
+		got := doc.Layers[i]
+		// 空的圖層讀回時是空的列表而不是 nil
+		if len(want.Shapes) == 0 {
+			want.Shapes = []shape.Shape{}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("layers[%d] = %+v, want %+v", i, got, want)
+		}
+	}
+}
+
+func TestUnmarshalLayerErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"version 1 without shapes", `{"version":1}`, `missing "shapes"`},
+		{"version 1 with layers", `{"version":1,"shapes":[],"layers":[]}`, `"layers" requires version 2`},
+		{"version 1 with grid", `{"version":1,"shapes":[],"grid":{"spacing":10,"subdivisions":1,"color":"#cccccc"}}`, `"grid" requires version 2`},
+		{"version 2 with shapes", `{"version":2,"shapes":[],"layers":[{"name":"","visible":true,"opacity":1,"shapes":[]}]}`, `"shapes" must be inside "layers"`},
+		{"missing layers", `{"version":2}`, `missing "layers"`},
+		{"empty layers", `{"version":2,"layers":[]}`, `missing "layers"`},
+		{"missing visible", `{"version":2,"layers":[{"name":"","opacity":1,"shapes":[]}]}`, `layers[0]: missing "visible"`},
+		{"missing opacity", `{"version":2,"layers":[{"name":"","visible":true,"shapes":[]}]}`, `layers[0]: missing "opacity"`},
+		{"missing layer shapes", `{"version":2,"layers":[{"name":"","visible":true,"opacity":1}]}`, `layers[0]: missing "shapes"`},
+		{"opacity above 1", `{"version":2,"layers":[{"name":"","visible":true,"opacity":1.5,"shapes":[]}]}`, "layers[0]: invalid opacity 1.5"},
+		{"negative opacity", `{"version":2,"layers":[{"name":"","visible":true,"opacity":1,"shapes":[]},{"name":"","visible":true,"opacity":-0.1,"shapes":[]}]}`, "layers[1]: invalid opacity -0.1"},
+		{"bad layer shape", `{"version":2,"layers":[{"name":"","visible":true,"opacity":1,"shapes":[{"type":"star"}]}]}`, `layers[0]: shapes[0]: unknown shape type "star"`},
+	}
+	for _, tt := range tests {
+		_, err := Unmarshal([]byte(tt.data))
+		if err == nil {
+			t.Errorf("%s: Unmarshal succeeded, want error containing %q", tt.name, tt.want)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: Unmarshal error = %q, want it to contain %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+//go:build js && wasm
+
+package canvas
+
+import (
+	"fmt"
+	"math"
+
+	"canvas-demo/internal/canvas/shape"
+)
+
+// 橡皮擦模式
+const (
+	eraserSplit  = "split"  // 擦去經過的部分，把筆畫分割成多段
+	eraserStroke = "stroke" // 刪除碰到的整條筆畫
+)
+
+// defaultEraserRadius 橡皮擦的預設半徑
+const defaultEraserRadius = 10.0
+
+// SetEraserRadius 設置橡皮擦半徑，最小為 1
+func (cm *CanvasManager) SetEraserRadius(radius float64) {
+	cm.eraserRadius = math.Max(radius, 1)
+	cm.redraw()
+}
+
+// SetEraserMode 設置橡皮擦模式：split 分割筆畫，stroke 刪除整條筆畫
+func (cm *CanvasManager) SetEraserMode(mode string) error {
+	if mode != eraserSplit && mode != eraserStroke {
+		return fmt.Errorf("unknown eraser mode %q", mode)
+	}
+	cm.eraserMode = mode
+	return nil
+}
+
+// startErasing 開始一次橡皮擦拖曳
+func (cm *CanvasManager) startErasing(p shape.Point) {
+	cm.stopTextEdit()
+	cm.isErasing = true
+	cm.lastX = p.X
+	cm.lastY = p.Y
+	cm.eraseAt(p)
+	cm.redraw()
+}
+
+// eraseTo 沿著上一個位置到 p 的路徑擦除，每次間隔半個半徑，避免快速移動時漏掉
+func (cm *CanvasManager) eraseTo(p shape.Point) {
+	last := shape.Point{X: cm.lastX, Y: cm.lastY}
+	steps := int(math.Ceil(math.Hypot(p.X-last.X, p.Y-last.Y) / (cm.eraserRadius / 2)))
+	for i := 1; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		cm.eraseAt(shape.Point{X: last.X + (p.X-last.X)*t, Y: last.Y + (p.Y-last.Y)*t})
+	}
+	cm.lastX = p.X
+	cm.lastY = p.Y
+	cm.repaint()
+}
+
+// eraseAt 擦除可見且未鎖定的圖層中碰到橡皮擦的筆畫
+func (cm *CanvasManager) eraseAt(p shape.Point) {
+	var steps []Command
+	for _, s := range cm.editableShapes() {
+		line, ok := s.(*shape.Line)
+		if !ok {
+			continue
+		}
+
+		step := &replaceShapeCommand{cm: cm, old: line}
+		if cm.eraserMode == eraserStroke {
+			if !line.Touches(p, cm.eraserRadius) {
+				continue
+			}
+		} else {
+			pieces, erased := line.Erase(p, cm.eraserRadius)
+			if !erased {
+				continue
+			}
+			for _, piece := range pieces {
+				step.replacement = append(step.replacement, piece)
+			}
+		}
+		steps = append(steps, step)
+	}
+
+	if len(steps) > 0 {
+		cm.history.Execute(&eraseCommand{steps: steps, gesture: cm.gesture})
+		cm.syncSelection()
+	}
+}
+
+// drawEraser 在滑鼠位置畫出橡皮擦的範圍
+func (cm *CanvasManager) drawEraser() {
+	if cm.currentTool != "eraser" || !cm.hovering {
+		return
+	}
+	cm.renderer.SetStrokeStyle("#888888")
+	cm.renderer.SetLineWidth(cm.view.screenLength(1))
+	cm.renderer.BeginPath()
+	cm.renderer.Arc(cm.hover.X, cm.hover.Y, cm.eraserRadius, 0, 2*math.Pi, false)
+	cm.renderer.Stroke()
+}
@@ -0,0 +1,121 @@
+//go:build js && wasm
+
+package canvas
+
+import (
+	"math"
+	"syscall/js"
+)
+
+// statsWindow 計算 FPS 與繪製時間的時間範圍（毫秒）
+const statsWindow = 1000.0
+
+// FrameStats 是繪製效能的統計，時間的單位為毫秒
+type FrameStats struct {
+	Frames        int     // 開始統計後繪製的影格數
+	FullRedraws   int     // 開始統計後重繪整個畫面的次數
+	FPS           float64 // 最近一秒繪製的影格數
+	FrameTime     float64 // 最近一秒每個影格的平均繪製時間
+	MaxFrameTime  float64 // 最近一秒最長的繪製時間
+	LastFrameTime float64 // 最後一個影格的繪製時間
+}
+
+// frameLoop 記錄下一個影格要繪製的內容。事件處理只標記變更，
+// 同一個影格內的多次變更在 requestAnimationFrame 時一起繪製
+type frameLoop struct {
+	callback    js.Func
+	requested   bool          // 已經要求下一個影格
+	redraw      bool          // 需要重繪整個畫面
+	repaint     bool          // 需要重繪變更的範圍
+	stroke      bool          // 繪製中的筆畫有新增的點
+	samples     []frameSample // 最近 statsWindow 內的影格
+	last        float64       // 最後一個影格的繪製時間
+	frames      int
+	fullRedraws int
+}
+
+// frameSample 是一個影格開始繪製的時間與花費的時間
+type frameSample struct {
+	at, duration float64
+}
+
+// requestFrame 要求在下一個影格繪製，已經要求過時不做任何事
+func (cm *CanvasManager) requestFrame() {
+	if cm.frame.requested {
+		return
+	}
+	cm.frame.requested = true
+	js.Global().Call("requestAnimationFrame", cm.frame.callback)
+}
+
+// renderFrame 是 requestAnimationFrame 的回呼：需要時重繪整個畫面，
+// 否則只畫出筆畫新增的線段並重繪變更的範圍
+func (cm *CanvasManager) renderFrame(this js.Value, args []js.Value) interface{} {
+	f := &cm.frame
+	redraw, repaint, stroke := f.redraw, f.repaint, f.stroke
+	f.requested, f.redraw, f.repaint, f.stroke = false, false, false, false
+
+	start := now()
+	switch {
+	case redraw:
+		cm.drawAll()
+	default:
+		if stroke {
+			cm.drawStroke()
+		}
+		if repaint {
+			cm.drawDamage()
+		}
+	}
+	end := now()
+
+	f.frames++
+	f.last = end - start
+	f.samples = append(f.samples, frameSample{at: start, duration: end - start})
+	f.prune(end)
+	return nil
+}
+
+// FrameStats 回傳繪製效能的統計
+func (cm *CanvasManager) FrameStats() FrameStats {
+	f := &cm.frame
+	f.prune(now())
+	stats := FrameStats{
+		Frames:        f.frames,
+		FullRedraws:   f.fullRedraws,
+		FPS:           float64(len(f.samples)) * 1000 / statsWindow,
+		LastFrameTime: f.last,
+	}
+	if len(f.samples) == 0 {
+		return stats
+	}
+	total := 0.0
+	for _, s := range f.samples {
+		total += s.duration
+		stats.MaxFrameTime = math.Max(stats.MaxFrameTime, s.duration)
+	}
+	stats.FrameTime = total / float64(len(f.samples))
+	return stats
+}
+
+// ResetFrameStats 清除繪製效能的統計
+func (cm *CanvasManager) ResetFrameStats() {
+	cm.frame.samples = nil
+	cm.frame.last = 0
+	cm.frame.frames = 0
+	cm.frame.fullRedraws = 0
+}
+
+// prune 移除 statsWindow 以前的影格
+func (f *frameLoop) prune(at float64) {
+	i := 0
+	for i < len(f.samples) && f.samples[i].at < at-statsWindow {
+		i++
+	}
+	f.samples = f.samples[i:]
+}
+
+// now 回傳 performance.now() 的時間（毫秒）
+func now() float64 {
+	return js.Global().Get("performance").Call("now").Float()
+}
@@ -0,0 +1,95 @@
+// Package grid 定義格線設定：主格線間隔、細分、顏色、是否顯示，
+// 以及移動、縮放與建立形狀時是否對齊格線。
+package grid
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"canvas-demo/internal/canvas/render"
+	"canvas-demo/internal/canvas/shape"
+)
+
+// minLineSpacing 格線在螢幕上的最小間隔，太密時不繪製細分的格線
+const minLineSpacing = 8.0
+
+// Grid 表示格線設定
+type Grid struct {
+	Spacing      float64 // 主格線的間隔（畫布座標）
+	Subdivisions int     // 每個主格細分的格數，1 表示不細分
+	Color        string
+	Visible      bool
+	Snap         bool // 移動、縮放與建立形狀時對齊細分的格線
+}
+
+// Default 回傳預設的格線設定：隱藏且不對齊
+func Default() Grid {
+	return Grid{Spacing: 100, Subdivisions: 5, Color: "#e0e0e0"}
+}
+
+// Validate 檢查格線設定是否合理
+func (g Grid) Validate() error {
+	switch {
+	case g.Spacing <= 0:
+		return fmt.Errorf("invalid grid spacing %g", g.Spacing)
+	case g.Subdivisions < 1:
+		return fmt.Errorf("invalid grid subdivisions %d", g.Subdivisions)
+	case g.Color == "":
+		return errors.New("grid is missing color")
+	}
+	return nil
+}
+
+// Step 回傳細分後的格線間隔，也就是對齊的單位
+func (g Grid) Step() float64 {
+	return g.Spacing / float64(g.Subdivisions)
+}
+
+// SnapPoint 回傳最接近 p 的格點
+func (g Grid) SnapPoint(p shape.Point) shape.Point {
+	step := g.Step()
+	return shape.Point{
+		X: math.Round(p.X/step) * step,
+		Y: math.Round(p.Y/step) * step,
+	}
+}
+
+// Draw 在 area 範圍內繪製格線（畫布座標），scale 為畫面的縮放倍率，
+// 用來讓線寬維持一個螢幕像素，並在格線太密時略過細分的格線
+func (g Grid) Draw(r render.Renderer, area shape.Bounds, scale float64) {
+	r.Save()
+	defer r.Restore()
+	r.SetStrokeStyle(g.Color)
+	r.SetLineWidth(1 / scale)
+
+	// 細分的格線以一半的透明度繪製
+	if step := g.Step(); g.Subdivisions > 1 && step*scale >= minLineSpacing {
+		r.SetGlobalAlpha(0.5)
+		drawLines(r, area, step)
+		r.SetGlobalAlpha(1)
+	}
+
+	spacing := g.Spacing
+	for spacing*scale < minLineSpacing {
+		spacing *= 2
+	}
+	drawLines(r, area, spacing)
+}
+
+// drawLines 在 area 範圍內每隔 spacing 畫一條水平與垂直的線
+func drawLines(r render.Renderer, area shape.Bounds, spacing float64) {
+	right := area.X + area.Width
+	bottom := area.Y + area.Height
+
+	r.BeginPath()
+	for x := math.Floor(area.X/spacing) * spacing; x <= right; x += spacing {
+		r.MoveTo(x, area.Y)
+		r.LineTo(x, bottom)
+	}
+	for y := math.Floor(area.Y/spacing) * spacing; y <= bottom; y += spacing {
+		r.MoveTo(area.X, y)
+		r.LineTo(right, y)
+	}
+	r.Stroke()
+}
@@ -0,0 +1,261 @@
+//go:build js && wasm
+
+package canvas
+
+import (
+	"math"
+	"sort"
+
+	"canvas-demo/internal/canvas/layer"
+	"canvas-demo/internal/canvas/shape"
+)
+
+const (
+	guideSnapDistance = 6.0 // 距離參考線多近（螢幕像素）時對齊
+	guideTickSize     = 4.0 // 等距提示兩端短線的半長（螢幕像素）
+	guideColor        = "#ff3b8d"
+	guideEpsilon      = 1e-6 // 判斷位置相同時容許的誤差
+)
+
+// guide 是拖曳時畫出的參考線：對齊線或等距提示的一段間距
+type guide struct {
+	from, to shape.Point
+	spacing  bool // 等距提示，兩端畫出短線
+}
+
+// spacing 是與同一列相鄰兩個形狀等距的位置
+type spacing struct {
+	lo   float64       // 移動的範圍在軸上的起點
+	gaps [2][2]float64 // 兩段相等的間距在軸上的起點與終點
+}
+
+// span 是軸上的一段範圍
+type span struct {
+	lo, hi float64
+}
+
+// snapMove 回傳移動後選取範圍的左上角：優先對齊其他形狀與畫面中心的邊緣、中心或等距的位置，
+// 沒有時對齊格線，並記錄要畫出的參考線。按住 Alt 時不對齊
+func (cm *CanvasManager) snapMove(m shape.Bounds) shape.Point {
+	cm.guides = nil
+	if cm.modifiers.Alt {
+		return shape.Point{X: m.X, Y: m.Y}
+	}
+
+	area := cm.visibleArea()
+	others := cm.guideTargets(area)
+	center := area.Center()
+	tolerance := cm.view.screenLength(guideSnapDistance)
+	gridPoint := cm.snapPoint(shape.Point{X: m.X, Y: m.Y})
+
+	// 等距的位置只和另一軸上的範圍有關，每個軸計算一次，對齊與參考線共用。
+	// y 軸的位置在 x 軸對齊之後計算；y 軸對齊只移動容差以內，x 軸沿用原本的結果
+	xs := equalSpacings(m, others, true)
+	if dx, ok := snapAxis(m, others, xs, center.X, true, tolerance); ok {
+		m.X += dx
+	} else {
+		m.X = gridPoint.X
+	}
+	ys := equalSpacings(m, others, false)
+	if dy, ok := snapAxis(m, others, ys, center.Y, false, tolerance); ok {
+		m.Y += dy
+	} else {
+		m.Y = gridPoint.Y
+	}
+
+	cm.guides = append(findGuides(m, others, xs, area, true), findGuides(m, others, ys, area, false)...)
+	return shape.Point{X: m.X, Y: m.Y}
+}
+
+// guideTargets 回傳畫面內可見圖層中未選取形狀的邊界框
+func (cm *CanvasManager) guideTargets(area shape.Bounds) []shape.Bounds {
+	candidates := cm.shapesIn(area)
+	var targets []shape.Bounds
+	for _, s := range layer.VisibleShapes(cm.layers) {
+		if candidates[s] && !cm.isSelected(s) {
+			targets = append(targets, s.GetBounds())
+		}
+	}
+	return targets
+}
+
+// snapAxis 找出讓 m 在一個軸上對齊的最小位移，horizontal 為 true 時是 x 軸。
+// spacings 是 equalSpacings 對同一軸的結果
+func snapAxis(m shape.Bounds, others []shape.Bounds, spacings []spacing, center float64, horizontal bool, tolerance float64) (float64, bool) {
+	best, found := 0.0, false
+	try := func(d float64) {
+		if math.Abs(d) <= tolerance && (!found || math.Abs(d) < math.Abs(best)) {
+			best, found = d, true
+		}
+	}
+
+	lo, hi := along(m, horizontal)
+	for _, v := range []float64{lo, (lo + hi) / 2, hi} {
+		try(center - v)
+		for _, o := range others {
+			olo, ohi := along(o, horizontal)
+			try(olo - v)
+			try((olo+ohi)/2 - v)
+			try(ohi - v)
+		}
+	}
+	for _, s := range spacings {
+		try(s.lo - lo)
+	}
+	return best, found
+}
+
+// equalSpacings 找出 m 與同一列（另一軸上有重疊）相鄰的兩個形狀等距的位置：
+// 在兩者之後、之前或正中間。結果只和 m 在另一軸上的範圍與大小有關
+func equalSpacings(m shape.Bounds, others []shape.Bounds, horizontal bool) []spacing {
+	lo, hi := along(m, horizontal)
+	size := hi - lo
+	clo, chi := along(m, !horizontal)
+
+	var row []span
+	for _, o := range others {
+		if olo, ohi := along(o, !horizontal); olo < chi && ohi > clo {
+			olo, ohi := along(o, horizontal)
+			row = append(row, span{olo, ohi})
+		}
+	}
+	sort.Slice(row, func(i, j int) bool { return row[i].lo < row[j].lo })
+
+	// 將重疊的形狀合併成一段，相鄰兩段之間的空隙就是相鄰兩個形狀的間距。
+	// 左邊的形狀終點等於前一段的終點，右邊的形狀起點等於後一段的起點
+	var runs []span
+	in := make([]int, len(row)) // 每個形狀所在的段
+	for i, o := range row {
+		if n := len(runs); n > 0 && o.lo <= runs[n-1].hi {
+			runs[n-1].hi = math.Max(runs[n-1].hi, o.hi)
+		} else {
+			runs = append(runs, o)
+		}
+		in[i] = len(runs) - 1
+	}
+
+	// free 檢查軸上 lo 到 hi 之間沒有其他形狀；各段依序排列且不重疊，可以二分搜尋
+	free := func(lo, hi float64) bool {
+		i := sort.Search(len(runs), func(i int) bool { return runs[i].hi > lo })
+		return i == len(runs) || runs[i].lo >= hi
+	}
+
+	var result []spacing
+	for k := 0; k+1 < len(runs); k++ {
+		ahi, blo := runs[k].hi, runs[k+1].lo
+		if inner := (blo - ahi - size) / 2; inner > 0 {
+			result = append(result, spacing{lo: ahi + inner, gaps: [2][2]float64{{ahi, ahi + inner}, {blo - inner, blo}}})
+		}
+	}
+	for i, o := range row {
+		k := in[i]
+		// 在右邊的形狀之後
+		if k > 0 && o.lo == runs[k].lo {
+			ahi := runs[k-1].hi
+			if gap := o.lo - ahi; free(o.hi, o.hi+gap) {
+				result = append(result, spacing{lo: o.hi + gap, gaps: [2][2]float64{{ahi, o.lo}, {o.hi, o.hi + gap}}})
+			}
+		}
+		// 在左邊的形狀之前
+		if k+1 < len(runs) && o.hi == runs[k].hi {
+			blo := runs[k+1].lo
+			if gap := blo - o.hi; free(o.lo-gap, o.lo) {
+				result = append(result, spacing{lo: o.lo - gap - size, gaps: [2][2]float64{{o.lo - gap, o.lo}, {o.hi, blo}}})
+			}
+		}
+	}
+	return result
+}
+
+// findGuides 產生 m 在一個軸上已對齊的參考線：與其他形狀或畫面中心對齊的線，以及等距提示。
+// spacings 是 equalSpacings 對同一軸的結果
+func findGuides(m shape.Bounds, others []shape.Bounds, spacings []spacing, area shape.Bounds, horizontal bool) []guide {
+	var guides []guide
+	lo, hi := along(m, horizontal)
+	clo, chi := along(m, !horizontal)
+
+	for _, v := range []float64{lo, (lo + hi) / 2, hi} {
+		from, to, matched := clo, chi, false
+		for _, o := range others {
+			olo, ohi := along(o, horizontal)
+			if !sameValue(v, olo) && !sameValue(v, (olo+ohi)/2) && !sameValue(v, ohi) {
+				continue
+			}
+			oclo, ochi := along(o, !horizontal)
+			from, to, matched = math.Min(from, oclo), math.Max(to, ochi), true
+		}
+		// 對齊畫面中心的線橫跨整個畫面
+		if alo, ahi := along(area, horizontal); sameValue(v, (alo+ahi)/2) {
+			alo, ahi := along(area, !horizontal)
+			from, to, matched = math.Min(from, alo), math.Max(to, ahi), true
+		}
+		if matched {
+			guides = append(guides, guide{from: axisPoint(v, from, horizontal), to: axisPoint(v, to, horizontal)})
+		}
+	}
+
+	// 等距提示畫在 m 另一軸的中間
+	c := (clo + chi) / 2
+	for _, s := range spacings {
+		if !sameValue(s.lo, lo) {
+			continue
+		}
+		for _, g := range s.gaps {
+			guides = append(guides, guide{from: axisPoint(g[0], c, horizontal), to: axisPoint(g[1], c, horizontal), spacing: true})
+		}
+	}
+	return guides
+}
+
+// drawGuides 繪製拖曳時的參考線
+func (cm *CanvasManager) drawGuides() {
+	if len(cm.guides) == 0 {
+		return
+	}
+	r := cm.renderer
+	r.SetStrokeStyle(guideColor)
+	r.SetLineWidth(cm.view.screenLength(1))
+	tick := cm.view.screenLength(guideTickSize)
+
+	r.BeginPath()
+	for _, g := range cm.guides {
+		r.MoveTo(g.from.X, g.from.Y)
+		r.LineTo(g.to.X, g.to.Y)
+		if !g.spacing {
+			continue
+		}
+		// 兩端畫出與間距垂直的短線
+		length := math.Hypot(g.to.X-g.from.X, g.to.Y-g.from.Y)
+		if length == 0 {
+			continue
+		}
+		nx := -(g.to.Y - g.from.Y) / length * tick
+		ny := (g.to.X - g.from.X) / length * tick
+		for _, p := range []shape.Point{g.from, g.to} {
+			r.MoveTo(p.X-nx, p.Y-ny)
+			r.LineTo(p.X+nx, p.Y+ny)
+		}
+	}
+	r.Stroke()
+}
+
+// along 回傳範圍在軸上的起點與終點，horizontal 為 true 時是 x 軸
+func along(b shape.Bounds, horizontal bool) (float64, float64) {
+	if horizontal {
+		return b.X, b.X + b.Width
+	}
+	return b.Y, b.Y + b.Height
+}
+
+// axisPoint 以軸上的位置 v 與另一軸上的位置 c 組成座標點
+func axisPoint(v, c float64, horizontal bool) shape.Point {
+	if horizontal {
+		return shape.Point{X: v, Y: c}
+	}
+	return shape.Point{X: c, Y: v}
+}
+
+// sameValue 檢查兩個位置是否相同，容許計算誤差
+func sameValue(a, b float64) bool {
+	return math.Abs(a-b) <= guideEpsilon*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
@@ -0,0 +1,199 @@
+//go:build js && wasm
+
+package canvas
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"canvas-demo/internal/canvas/shape"
+)
+
+// box 建立左上角在 (x, y)、大小為 w×h 的範圍
+func box(x, y, w, h float64) shape.Bounds {
+	return shape.Bounds{X: x, Y: y, Width: w, Height: h}
+}
+
+// sortSpacings 依位置排序並移除重複的結果
+func sortSpacings(spacings []spacing) []spacing {
+	sort.Slice(spacings, func(i, j int) bool {
+		a, b := spacings[i], spacings[j]
+		if a.lo != b.lo {
+			return a.lo < b.lo
+		}
+		return a.gaps[0][0] < b.gaps[0][0]
+	})
+	var result []spacing
+	for i, s := range spacings {
+		if i == 0 || s != spacings[i-1] {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func TestEqualSpacings(t *testing.T) {
+	m := box(100, 0, 10, 10)
+	row := []shape.Bounds{box(30, 5, 10, 10), box(0, -5, 10, 10)}
+	// 另一軸上沒有重疊的形狀不在同一列
+	elsewhere := box(70, 50, 10, 10)
+
+	want := []spacing{
+		{lo: -30, gaps: [2][2]float64{{-20, 0}, {10, 30}}}, // 在左邊之前
+		{lo: 15, gaps: [2][2]float64{{10, 15}, {25, 30}}},  // 正中間
+		{lo: 60, gaps: [2][2]float64{{10, 30}, {40, 60}}},  // 在右邊之後
+	}
+	got := sortSpacings(equalSpacings(m, append(row, elsewhere), true))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("equalSpacings = %v, want %v", got, want)
+	}
+
+	// 之後的位置被其他形狀擋住
+	got = sortSpacings(equalSpacings(m, append(row, box(55, 0, 10, 10)), true))
+	for _, s := range got {
+		if s.lo == 60 {
+			t.Errorf("equalSpacings returned a blocked position: %v", got)
+		}
+	}
+	// 間距比形狀小時沒有中間的位置
+	got = sortSpacings(equalSpacings(box(100, 0, 30, 10), row, true))
+	for _, s := range got {
+		if s.lo > 10 && s.lo < 30 {
+			t.Errorf("equalSpacings returned a middle position in a gap narrower than the shape: %v", got)
+		}
+	}
+}
+
+// equalSpacingsPairwise 逐一檢查每一對形狀，作為 equalSpacings 的參考
+func equalSpacingsPairwise(m shape.Bounds, others []shape.Bounds, horizontal bool) []spacing {
+	lo, hi := along(m, horizontal)
+	size := hi - lo
+	clo, chi := along(m, !horizontal)
+
+	var row []shape.Bounds
+	for _, o := range others {
+		if olo, ohi := along(o, !horizontal); olo < chi && ohi > clo {
+			row = append(row, o)
+		}
+	}
+	adjacent := func(lo, hi float64) bool {
+		for _, o := range row {
+			if olo, ohi := along(o, horizontal); olo < hi && ohi > lo {
+				return false
+			}
+		}
+		return true
+	}
+
+	var result []spacing
+	for i, a := range row {
+		alo, ahi := along(a, horizontal)
+		for j, b := range row {
+			blo, bhi := along(b, horizontal)
+			gap := blo - ahi
+			if i == j || gap <= 0 || !adjacent(ahi, blo) {
+				continue
+			}
+			if adjacent(bhi, bhi+gap) {
+				result = append(result, spacing{lo: bhi + gap, gaps: [2][2]float64{{ahi, blo}, {bhi, bhi + gap}}})
+			}
+			if adjacent(alo-gap, alo) {
+				result = append(result, spacing{lo: alo - gap - size, gaps: [2][2]float64{{alo - gap, alo}, {ahi, blo}}})
+			}
+			if inner := (gap - size) / 2; inner > 0 {
+				result = append(result, spacing{lo: ahi + inner, gaps: [2][2]float64{{ahi, ahi + inner}, {blo - inner, blo}}})
+			}
+		}
+	}
+	return result
+}
+
+func TestEqualSpacingsMatchesPairwise(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	// 整數座標讓形狀經常重疊或對齊
+	coord := func(n int) float64 { return float64(rng.Intn(n)) }
+	for i := 0; i < 200; i++ {
+		others := make([]shape.Bounds, 2+rng.Intn(15))
+		for j := range others {
+			others[j] = box(coord(300), coord(60), 1+coord(40), 1+coord(40))
+		}
+		m := box(coord(300), coord(60), 1+coord(40), 1+coord(40))
+		for _, horizontal := range []bool{true, false} {
+			got := sortSpacings(equalSpacings(m, others, horizontal))
+			want := sortSpacings(equalSpacingsPairwise(m, others, horizontal))
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("equalSpacings(%v, %v, %v) = %v, want %v", m, others, horizontal, got, want)
+			}
+		}
+	}
+}
+
+func TestSnapAxis(t *testing.T) {
+	others := []shape.Bounds{box(0, 0, 20, 20), box(100, 100, 40, 40)}
+	tests := []struct {
+		name       string
+		m          shape.Bounds
+		center     float64
+		horizontal bool
+		want       float64
+		ok         bool
+	}{
+		{"left edges", box(3, 300, 50, 50), 1000, true, -3, true},
+		{"left to right edge", box(22, 300, 50, 50), 1000, true, -2, true},
+		{"centers", box(94, 300, 50, 50), 1000, true, 1, true},
+		{"screen center", box(452, 300, 50, 50), 500, true, -2, true},
+		{"closest wins", box(98, 300, 50, 50), 1000, true, 2, true}, // 中心也在 120 的容差內
+		{"vertical", box(300, 136, 50, 50), 1000, false, 4, true},
+		{"too far", box(40, 300, 50, 50), 1000, true, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := snapAxis(tt.m, others, nil, tt.center, tt.horizontal, 5)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s: snapAxis = %g, %v; want %g, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	// 等距的位置也可以對齊
+	row := []shape.Bounds{box(0, 0, 10, 10), box(30, 0, 10, 10)}
+	m := box(63, 0, 10, 10)
+	spacings := equalSpacings(m, row, true)
+	if got, ok := snapAxis(m, row, spacings, 1000, true, 5); !ok || got != -3 {
+		t.Errorf("snapAxis to equal spacing = %g, %v; want -3, true", got, ok)
+	}
+}
+
+func TestFindGuidesSpacing(t *testing.T) {
+	row := []shape.Bounds{box(0, 0, 10, 10), box(30, 0, 10, 10)}
+	m := box(60, 0, 10, 10)
+	area := box(-1000, -1000, 100, 100)
+	guides := findGuides(m, row, equalSpacings(m, row, true), area, true)
+
+	var spacingGuides []guide
+	for _, g := range guides {
+		if g.spacing {
+			spacingGuides = append(spacingGuides, g)
+		}
+	}
+	want := []guide{
+		{from: shape.Point{X: 10, Y: 5}, to: shape.Point{X: 30, Y: 5}, spacing: true},
+		{from: shape.Point{X: 40, Y: 5}, to: shape.Point{X: 60, Y: 5}, spacing: true},
+	}
+	if !reflect.DeepEqual(spacingGuides, want) {
+		t.Errorf("spacing guides = %v, want %v", spacingGuides, want)
+	}
+}
+
+func BenchmarkEqualSpacings(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	others := make([]shape.Bounds, 2000)
+	for i := range others {
+		others[i] = box(rng.Float64()*5000, rng.Float64()*200, 10+rng.Float64()*30, 10+rng.Float64()*30)
+	}
+	m := box(100, 50, 20, 100)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		equalSpacings(m, others, true)
+	}
+}
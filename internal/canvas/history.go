@@ -0,0 +1,134 @@
+package canvas
+
+// Command 表示一個可以復原與重做的操作
+type Command interface {
+	Do()
+	Undo()
+}
+
+// merger 由可合併的命令實作，next 已經執行過；
+// 回傳 true 表示 next 已併入此命令，不需要另外記錄
+type merger interface {
+	Merge(next Command) bool
+}
+
+// defaultHistoryLimit 預設保留的歷史紀錄數量
+const defaultHistoryLimit = 100
+
+// History 管理復原與重做堆疊
+type History struct {
+	undoStack []Command
+	redoStack []Command
+	limit     int
+	onChange  func(cmd Command) // 命令執行、記錄、復原或重做後呼叫
+}
+
+// NewHistory 創建新的歷史紀錄，limit <= 0 表示不限制數量
+func NewHistory(limit int) *History {
+	return &History{
+		undoStack: make([]Command, 0),
+		redoStack: make([]Command, 0),
+		limit:     limit,
+	}
+}
+
+// Execute 執行命令並記錄
+func (h *History) Execute(cmd Command) {
+	cmd.Do()
+	h.Push(cmd)
+}
+
+// OnChange 設置每次命令執行、記錄、復原或重做後呼叫的函式
+func (h *History) OnChange(fn func(cmd Command)) {
+	h.onChange = fn
+}
+
+// Push 記錄一個已經執行過的命令
+func (h *History) Push(cmd Command) {
+	h.changed(cmd)
+
+	// 新的操作會讓重做堆疊失效
+	h.redoStack = h.redoStack[:0]
+
+	// 嘗試與上一個命令合併（例如同一次拖曳中的多次移動）
+	if n := len(h.undoStack); n > 0 {
+		if m, ok := h.undoStack[n-1].(merger); ok && m.Merge(cmd) {
+			return
+		}
+	}
+
+	h.undoStack = append(h.undoStack, cmd)
+	h.trim()
+}
+
+// Undo 復原最後一個命令，沒有可復原的命令時回傳 false
+func (h *History) Undo() bool {
+	n := len(h.undoStack)
+	if n == 0 {
+		return false
+	}
+
+	cmd := h.undoStack[n-1]
+	h.undoStack = h.undoStack[:n-1]
+	cmd.Undo()
+	h.changed(cmd)
+	h.redoStack = append(h.redoStack, cmd)
+	return true
+}
+
+// Redo 重做最後一個被復原的命令，沒有可重做的命令時回傳 false
+func (h *History) Redo() bool {
+	n := len(h.redoStack)
+	if n == 0 {
+		return false
+	}
+
+	cmd := h.redoStack[n-1]
+	h.redoStack = h.redoStack[:n-1]
+	cmd.Do()
+	h.changed(cmd)
+	h.undoStack = append(h.undoStack, cmd)
+	return true
+}
+
+// CanUndo 回傳是否有可復原的命令
+func (h *History) CanUndo() bool {
+	return len(h.undoStack) > 0
+}
+
+// CanRedo 回傳是否有可重做的命令
+func (h *History) CanRedo() bool {
+	return len(h.redoStack) > 0
+}
+
+// SetLimit 設置歷史紀錄的最大數量，超出的舊紀錄會被丟棄
+func (h *History) SetLimit(limit int) {
+	h.limit = limit
+	h.trim()
+}
+
+// Clear 清除所有歷史紀錄
+func (h *History) Clear() {
+	h.undoStack = h.undoStack[:0]
+	h.redoStack = h.redoStack[:0]
+}
+
+// trim 丟棄超出上限的最舊紀錄
+func (h *History) trim() {
+	if h.limit <= 0 || len(h.undoStack) <= h.limit {
+		return
+	}
+	excess := len(h.undoStack) - h.limit
+	// 清除引用，讓被丟棄的命令可以被回收
+	for i := 0; i < excess; i++ {
+		h.undoStack[i] = nil
+	}
+	h.undoStack = append(h.undoStack[:0], h.undoStack[excess:]...)
+}
+
+// changed 通知命令造成的變更
+func (h *History) changed(cmd Command) {
+	if h.onChange != nil {
+		h.onChange(cmd)
+	}
+}
@@ -0,0 +1,190 @@
+package canvas
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// testCommand 將數值加到 state 上，同一個 gesture 的命令合併成一個
+type testCommand struct {
+	state   *int
+	delta   int
+	gesture int // 0 表示不合併
+}
+
+func (c *testCommand) Do()   { *c.state += c.delta }
+func (c *testCommand) Undo() { *c.state -= c.delta }
+
+func (c *testCommand) Merge(next Command) bool {
+	n, ok := next.(*testCommand)
+	if !ok || c.gesture == 0 || n.gesture != c.gesture {
+		return false
+	}
+	c.delta += n.delta
+	return true
+}
+
+func (c *testCommand) String() string { return fmt.Sprintf("%+d", c.delta) }
+
+func TestHistoryUndoRedo(t *testing.T) {
+	state := 0
+	h := NewHistory(0)
+	if h.CanUndo() || h.CanRedo() || h.Undo() || h.Redo() {
+		t.Fatal("new history can undo or redo")
+	}
+
+	h.Execute(&testCommand{state: &state, delta: 1})
+	h.Execute(&testCommand{state: &state, delta: 10})
+	h.Execute(&testCommand{state: &state, delta: 100})
+	if state != 111 {
+		t.Fatalf("state after Execute = %d, want 111", state)
+	}
+
+	// 以相反的順序復原，再以原本的順序重做
+	for _, want := range []int{11, 1, 0} {
+		if !h.Undo() {
+			t.Fatal("Undo() = false")
+		}
+		if state != want {
+			t.Errorf("state after Undo = %d, want %d", state, want)
+		}
+	}
+	if h.CanUndo() || h.Undo() {
+		t.Error("can undo past the first command")
+	}
+	for _, want := range []int{1, 11, 111} {
+		if !h.Redo() {
+			t.Fatal("Redo() = false")
+		}
+		if state != want {
+			t.Errorf("state after Redo = %d, want %d", state, want)
+		}
+	}
+	if h.CanRedo() || h.Redo() {
+		t.Error("can redo past the last command")
+	}
+}
+
+func TestHistoryPushClearsRedo(t *testing.T) {
+	state := 0
+	h := NewHistory(0)
+	h.Execute(&testCommand{state: &state, delta: 1})
+	h.Execute(&testCommand{state: &state, delta: 2})
+	h.Undo()
+	if !h.CanRedo() {
+		t.Fatal("CanRedo() = false after Undo")
+	}
+
+	// Push 記錄已經執行過的命令，不會再執行一次
+	state += 5
+	h.Push(&testCommand{state: &state, delta: 5})
+	if state != 6 {
+		t.Errorf("state after Push = %d, want 6", state)
+	}
+	if h.CanRedo() {
+		t.Error("new command did not clear redo")
+	}
+	h.Undo()
+	h.Undo()
+	if state != 0 {
+		t.Errorf("state after undoing everything = %d, want 0", state)
+	}
+}
+
+func TestHistoryMerge(t *testing.T) {
+	state := 0
+	h := NewHistory(0)
+	// 同一次拖曳的三個命令與下一次拖曳的兩個命令
+	for _, c := range []struct{ delta, gesture int }{{1, 1}, {2, 1}, {3, 1}, {10, 2}, {20, 2}} {
+		h.Execute(&testCommand{state: &state, delta: c.delta, gesture: c.gesture})
+	}
+	if state != 36 {
+		t.Fatalf("state = %d, want 36", state)
+	}
+
+	if want := []string{"+6", "+30"}; !reflect.DeepEqual(stackStrings(h.undoStack), want) {
+		t.Errorf("undo stack = %v, want %v", stackStrings(h.undoStack), want)
+	}
+	h.Undo()
+	if state != 6 {
+		t.Errorf("state after undoing the second gesture = %d, want 6", state)
+	}
+	h.Undo()
+	if state != 0 || h.CanUndo() {
+		t.Errorf("state after undoing the first gesture = %d, want 0 with nothing left", state)
+	}
+
+	// 重做合併後的命令，之後不同 gesture 的命令另外記錄
+	h.Redo()
+	h.Execute(&testCommand{state: &state, delta: 100, gesture: 3})
+	if want := []string{"+6", "+100"}; !reflect.DeepEqual(stackStrings(h.undoStack), want) {
+		t.Errorf("undo stack = %v, want %v", stackStrings(h.undoStack), want)
+	}
+}
+
+func TestHistoryLimit(t *testing.T) {
+	state := 0
+	h := NewHistory(3)
+	for i := 1; i <= 5; i++ {
+		h.Execute(&testCommand{state: &state, delta: i})
+	}
+	if want := []string{"+3", "+4", "+5"}; !reflect.DeepEqual(stackStrings(h.undoStack), want) {
+		t.Fatalf("undo stack = %v, want %v", stackStrings(h.undoStack), want)
+	}
+	for h.Undo() {
+	}
+	// 最舊的兩個命令已經被丟棄，無法復原
+	if state != 3 {
+		t.Errorf("state after undoing everything = %d, want 3", state)
+	}
+
+	// 縮小上限時立即丟棄超出的紀錄，0 表示不限制
+	for h.Redo() {
+	}
+	h.SetLimit(1)
+	if want := []string{"+5"}; !reflect.DeepEqual(stackStrings(h.undoStack), want) {
+		t.Errorf("undo stack after SetLimit(1) = %v, want %v", stackStrings(h.undoStack), want)
+	}
+	h.SetLimit(0)
+	for i := 0; i < 10; i++ {
+		h.Execute(&testCommand{state: &state, delta: 1})
+	}
+	if n := len(h.undoStack); n != 11 {
+		t.Errorf("unlimited history has %d commands, want 11", n)
+	}
+
+	h.Clear()
+	if h.CanUndo() || h.CanRedo() {
+		t.Error("history is not empty after Clear")
+	}
+}
+
+func TestHistoryOnChange(t *testing.T) {
+	state := 0
+	h := NewHistory(0)
+	var changed []Command
+	h.OnChange(func(cmd Command) { changed = append(changed, cmd) })
+
+	a := &testCommand{state: &state, delta: 1, gesture: 1}
+	b := &testCommand{state: &state, delta: 2, gesture: 1}
+	h.Execute(a)
+	h.Execute(b) // 合併的命令也要通知
+	h.Undo()
+	h.Redo()
+	h.Undo()
+	h.Undo() // 沒有可復原的命令時不通知
+
+	if want := []Command{a, b, a, a, a}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("OnChange calls = %v, want %v", changed, want)
+	}
+}
+
+// stackStrings 回傳堆疊中每個命令的內容
+func stackStrings(stack []Command) []string {
+	s := make([]string, len(stack))
+	for i, c := range stack {
+		s[i] = fmt.Sprint(c)
+	}
+	return s
+}
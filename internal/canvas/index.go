@@ -0,0 +1,47 @@
+//go:build js && wasm
+
+package canvas
+
+import (
+	"canvas-demo/internal/canvas/layer"
+	"canvas-demo/internal/canvas/shape"
+)
+
+// pickMargin 點選時查詢索引多保留的範圍（螢幕像素），涵蓋形狀 Contains 的容差
+const pickMargin = 8.0
+
+// indexCommand 在命令改變形狀的位置或大小後更新空間索引，並記錄形狀原本與新的範圍需要重繪。
+// 加入與移除形狀經由 insertShape、removeShape 與圖層的增減更新
+func (cm *CanvasManager) indexCommand(cmd Command) {
+	if r, ok := cmd.(reshaper); ok {
+		for _, s := range r.reshaped() {
+			if old, ok := cm.index.Bounds(s); ok {
+				cm.invalidate(old)
+			}
+			cm.index.Update(s)
+			cm.invalidate(s.GetBounds())
+		}
+	}
+}
+
+// rebuildIndex 以所有圖層的形狀重建空間索引
+func (cm *CanvasManager) rebuildIndex() {
+	cm.index.Clear()
+	for _, s := range layer.Shapes(cm.layers) {
+		cm.index.Insert(s)
+	}
+}
+
+// shapesIn 回傳邊界與 area 重疊的形狀，包含隱藏與鎖定圖層中的形狀。
+// 編輯中的文字會隨輸入改變大小，結束編輯時才更新索引，所以一律包含
+func (cm *CanvasManager) shapesIn(area shape.Bounds) map[shape.Shape]bool {
+	found := cm.index.Search(area)
+	set := make(map[shape.Shape]bool, len(found)+1)
+	for _, s := range found {
+		set[s] = true
+	}
+	if cm.editingText != nil {
+		set[cm.editingText] = true
+	}
+	return set
+}
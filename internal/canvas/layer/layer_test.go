@@ -0,0 +1,129 @@
+package layer
+
+import (
+	"reflect"
+	"testing"
+
+	"canvas-demo/internal/canvas/render"
+	"canvas-demo/internal/canvas/shape"
+)
+
+// newShapes 建立 n 個位置不同的矩形
+func newShapes(n int) []shape.Shape {
+	shapes := make([]shape.Shape, n)
+	for i := range shapes {
+		r := shape.NewRect(shape.Style{StrokeStyle: "#000000", LineWidth: 1})
+		r.SetCorners(shape.Point{X: float64(i * 10)}, shape.Point{X: float64(i*10 + 5), Y: 5})
+		shapes[i] = r
+	}
+	return shapes
+}
+
+func TestInsertRemove(t *testing.T) {
+	s := newShapes(4)
+	l := New(1, DefaultName(1))
+	l.Insert(0, s[1])
+	l.Insert(0, s[0])   // 最下層
+	l.Insert(-1, s[3])  // 超出範圍時加到最上層
+	l.Insert(2, s[2])   // 插入到 s[3] 之前
+	l.Insert(100, s[3]) // 同一個形狀也會再加入一次
+	if want := []shape.Shape{s[0], s[1], s[2], s[3], s[3]}; !reflect.DeepEqual(l.Shapes, want) {
+		t.Fatalf("Shapes after Insert = %v, want %v", l.Shapes, want)
+	}
+
+	if i := l.IndexOf(s[2]); i != 2 {
+		t.Errorf("IndexOf(s[2]) = %d, want 2", i)
+	}
+	if i := l.Remove(s[1]); i != 1 {
+		t.Errorf("Remove(s[1]) = %d, want 1", i)
+	}
+	if i := l.Remove(s[1]); i != -1 {
+		t.Errorf("Remove of a missing shape = %d, want -1", i)
+	}
+	if i := l.IndexOf(s[1]); i != -1 {
+		t.Errorf("IndexOf of a removed shape = %d, want -1", i)
+	}
+	// 重複的形狀只移除最下面的一個
+	if i := l.Remove(s[3]); i != 2 {
+		t.Errorf("Remove(s[3]) = %d, want 2", i)
+	}
+	if want := []shape.Shape{s[0], s[2], s[3]}; !reflect.DeepEqual(l.Shapes, want) {
+		t.Errorf("Shapes after Remove = %v, want %v", l.Shapes, want)
+	}
+}
+
+func TestShapes(t *testing.T) {
+	s := newShapes(3)
+	bottom, hidden, top := New(1, "bottom"), New(2, "hidden"), New(3, "top")
+	bottom.Shapes = s[:1]
+	hidden.Shapes = s[1:2]
+	hidden.Visible = false
+	top.Shapes = s[2:]
+	layers := []*Layer{bottom, hidden, top}
+
+	if got := Shapes(layers); !reflect.DeepEqual(got, s) {
+		t.Errorf("Shapes() = %v, want %v", got, s)
+	}
+	if got, want := VisibleShapes(layers), []shape.Shape{s[0], s[2]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("VisibleShapes() = %v, want %v", got, want)
+	}
+}
+
+func TestEditable(t *testing.T) {
+	l := New(1, "")
+	if !l.Editable() {
+		t.Error("new layer is not editable")
+	}
+	l.Locked = true
+	if l.Editable() {
+		t.Error("locked layer is editable")
+	}
+	l.Locked, l.Visible = false, false
+	if l.Editable() {
+		t.Error("hidden layer is editable")
+	}
+}
+
+func TestDraw(t *testing.T) {
+	l := New(1, "")
+	l.Opacity = 0.5
+	l.Shapes = newShapes(2)
+
+	r := render.NewRecorder()
+	l.Draw(r)
+	methods := r.Methods()
+	if len(methods) < 3 || methods[0] != "save" || methods[len(methods)-1] != "restore" {
+		t.Fatalf("Draw calls = %v, want save ... restore", methods)
+	}
+	if c := r.Calls[1]; c.Method != "globalAlpha" || !reflect.DeepEqual(c.Args, []interface{}{0.5}) {
+		t.Errorf("second call = %v, want globalAlpha(0.5)", c)
+	}
+	if n := count(methods, "rect"); n != 2 {
+		t.Errorf("drew %d rects, want 2", n)
+	}
+
+	// 只繪製 include 中的形狀
+	r.Reset()
+	l.DrawOnly(r, map[shape.Shape]bool{l.Shapes[1]: true})
+	if n := count(r.Methods(), "rect"); n != 1 {
+		t.Errorf("DrawOnly drew %d rects, want 1", n)
+	}
+
+	// 隱藏的圖層完全不繪製
+	r.Reset()
+	l.Visible = false
+	l.Draw(r)
+	if len(r.Calls) != 0 {
+		t.Errorf("hidden layer drew %v", r.Methods())
+	}
+}
+
+func count(methods []string, method string) int {
+	n := 0
+	for _, m := range methods {
+		if m == method {
+			n++
+		}
+	}
+	return n
+}
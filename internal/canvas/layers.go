@@ -0,0 +1,261 @@
+//go:build js && wasm
+
+package canvas
+
+import (
+	"fmt"
+	"strings"
+
+	"canvas-demo/internal/canvas/layer"
+	"canvas-demo/internal/canvas/shape"
+)
+
+// LayerInfo 是提供給圖層面板的圖層資訊
+type LayerInfo struct {
+	ID         int
+	Name       string
+	Visible    bool
+	Locked     bool
+	Opacity    float64
+	Active     bool
+	ShapeCount int
+}
+
+// Layers 回傳所有圖層的資訊，依由下往上的順序
+func (cm *CanvasManager) Layers() []LayerInfo {
+	infos := make([]LayerInfo, len(cm.layers))
+	for i, l := range cm.layers {
+		infos[i] = LayerInfo{
+			ID:         l.ID,
+			Name:       l.Name,
+			Visible:    l.Visible,
+			Locked:     l.Locked,
+			Opacity:    l.Opacity,
+			Active:     l == cm.activeLayer,
+			ShapeCount: len(l.Shapes),
+		}
+	}
+	return infos
+}
+
+// AddLayer 在作用中的圖層上方新增圖層並設為作用中，name 為空時使用預設名稱，回傳新圖層的 ID
+func (cm *CanvasManager) AddLayer(name string) int {
+	if strings.TrimSpace(name) == "" {
+		name = layer.DefaultName(cm.nextLayerID)
+	}
+	l := layer.New(cm.nextLayerID, name)
+	cm.nextLayerID++
+
+	cm.stopTextEdit()
+	cm.history.Execute(&addLayerCommand{cm: cm, layer: l, index: cm.layerIndex(cm.activeLayer) + 1})
+	cm.redraw()
+	return l.ID
+}
+
+// RemoveLayer 刪除圖層與其中的形狀，不能刪除最後一個圖層
+func (cm *CanvasManager) RemoveLayer(id int) error {
+	l, err := cm.findLayer(id)
+	if err != nil {
+		return err
+	}
+	if len(cm.layers) == 1 {
+		return fmt.Errorf("layer %d: cannot remove the last layer", id)
+	}
+
+	cm.stopTextEdit()
+	cm.history.Execute(&removeLayerCommand{cm: cm, layer: l})
+	cm.syncSelection()
+	cm.redraw()
+	return nil
+}
+
+// MoveLayer 將圖層移到 index 的位置（0 為最下層）
+func (cm *CanvasManager) MoveLayer(id, index int) error {
+	l, err := cm.findLayer(id)
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= len(cm.layers) {
+		return fmt.Errorf("layer %d: index %d out of range", id, index)
+	}
+
+	from := cm.layerIndex(l)
+	if from == index {
+		return nil
+	}
+	cm.history.Execute(&moveLayerCommand{cm: cm, layer: l, from: from, to: index})
+	cm.redraw()
+	return nil
+}
+
+// RenameLayer 重新命名圖層
+func (cm *CanvasManager) RenameLayer(id int, name string) error {
+	l, err := cm.findLayer(id)
+	if err != nil {
+		return err
+	}
+	l.Name = name
+	return nil
+}
+
+// SetLayerVisible 顯示或隱藏圖層，隱藏的圖層中的形狀會被取消選取
+func (cm *CanvasManager) SetLayerVisible(id int, visible bool) error {
+	l, err := cm.findLayer(id)
+	if err != nil {
+		return err
+	}
+	cm.stopTextEdit()
+	l.Visible = visible
+	cm.syncSelection()
+	cm.redraw()
+	return nil
+}
+
+// SetLayerLocked 鎖定或解除鎖定圖層，鎖定的圖層中的形狀會被取消選取
+func (cm *CanvasManager) SetLayerLocked(id int, locked bool) error {
+	l, err := cm.findLayer(id)
+	if err != nil {
+		return err
+	}
+	cm.stopTextEdit()
+	l.Locked = locked
+	cm.syncSelection()
+	cm.redraw()
+	return nil
+}
+
+// SetLayerOpacity 設置圖層的透明度（0 到 1）
+func (cm *CanvasManager) SetLayerOpacity(id int, opacity float64) error {
+	l, err := cm.findLayer(id)
+	if err != nil {
+		return err
+	}
+	if opacity < 0 || opacity > 1 {
+		return fmt.Errorf("layer %d: invalid opacity %g", id, opacity)
+	}
+	l.Opacity = opacity
+	cm.redraw()
+	return nil
+}
+
+// SetActiveLayer 設置新形狀要加入的圖層
+func (cm *CanvasManager) SetActiveLayer(id int) error {
+	l, err := cm.findLayer(id)
+	if err != nil {
+		return err
+	}
+	cm.activeLayer = l
+	return nil
+}
+
+// findLayer 以 ID 尋找圖層
+func (cm *CanvasManager) findLayer(id int) (*layer.Layer, error) {
+	for _, l := range cm.layers {
+		if l.ID == id {
+			return l, nil
+		}
+	}
+	return nil, fmt.Errorf("layer %d not found", id)
+}
+
+// layerIndex 回傳圖層的位置，找不到時回傳 -1
+func (cm *CanvasManager) layerIndex(l *layer.Layer) int {
+	for i, existing := range cm.layers {
+		if existing == l {
+			return i
+		}
+	}
+	return -1
+}
+
+// insertLayer 將圖層插入到指定位置，超出範圍時加到最上層
+func (cm *CanvasManager) insertLayer(index int, l *layer.Layer) {
+	if index < 0 || index > len(cm.layers) {
+		index = len(cm.layers)
+	}
+	cm.layers = append(cm.layers, nil)
+	copy(cm.layers[index+1:], cm.layers[index:])
+	cm.layers[index] = l
+	for _, s := range l.Shapes {
+		cm.index.Insert(s)
+		cm.invalidate(s.GetBounds())
+	}
+}
+
+// removeLayer 移除圖層並回傳原本的位置
+func (cm *CanvasManager) removeLayer(l *layer.Layer) int {
+	index := cm.layerIndex(l)
+	if index < 0 {
+		return -1
+	}
+	cm.layers = append(cm.layers[:index], cm.layers[index+1:]...)
+	for _, s := range l.Shapes {
+		cm.invalidate(s.GetBounds())
+		cm.index.Remove(s)
+	}
+	return index
+}
+
+// layerOf 回傳形狀所在的圖層與位置，找不到時回傳 nil 與 -1
+func (cm *CanvasManager) layerOf(s shape.Shape) (*layer.Layer, int) {
+	for _, l := range cm.layers {
+		if i := l.IndexOf(s); i >= 0 {
+			return l, i
+		}
+	}
+	return nil, -1
+}
+
+// addShape 將新形狀加到作用中圖層的最上層，記錄為可復原的步驟
+func (cm *CanvasManager) addShape(s shape.Shape) {
+	cm.history.Execute(&addShapeCommand{
+		cm:    cm,
+		layer: cm.activeLayer,
+		shape: s,
+		index: len(cm.activeLayer.Shapes),
+	})
+}
+
+// insertShape 將形狀插入到圖層的指定位置，超出範圍時加到最上層
+func (cm *CanvasManager) insertShape(l *layer.Layer, index int, s shape.Shape) {
+	l.Insert(index, s)
+	cm.index.Insert(s)
+	cm.invalidate(s.GetBounds())
+}
+
+// removeShape 從所在的圖層中移除形狀並回傳原本的圖層與位置
+func (cm *CanvasManager) removeShape(s shape.Shape) (*layer.Layer, int) {
+	l, index := cm.layerOf(s)
+	if l == nil {
+		return nil, -1
+	}
+
+	if cm.editingText == s {
+		cm.editingText = nil
+	}
+	// 文字物件需要移除 HTML 元素
+	s.Delete()
+	l.Remove(s)
+	if b, ok := cm.index.Bounds(s); ok {
+		cm.invalidate(b)
+	}
+	cm.index.Remove(s)
+	return l, index
+}
+
+// canSelect 檢查形狀是否在畫布上且所在的圖層可見、未鎖定
+func (cm *CanvasManager) canSelect(s shape.Shape) bool {
+	l, _ := cm.layerOf(s)
+	return l != nil && l.Editable()
+}
+
+// editableShapes 回傳可見且未鎖定的圖層中的形狀，依由下往上的順序
+func (cm *CanvasManager) editableShapes() []shape.Shape {
+	var shapes []shape.Shape
+	for _, l := range cm.layers {
+		if l.Editable() {
+			shapes = append(shapes, l.Shapes...)
+		}
+	}
+	return shapes
+}
@@ -3,29 +3,104 @@
 package canvas
 
 import (
+	"bytes"
 	"math"
+	"sort"
 	"syscall/js"
 
+	"canvas-demo/internal/canvas/document"
+	"canvas-demo/internal/canvas/grid"
+	"canvas-demo/internal/canvas/layer"
+	"canvas-demo/internal/canvas/raster"
+	"canvas-demo/internal/canvas/render"
 	"canvas-demo/internal/canvas/shape"
+	"canvas-demo/internal/canvas/spatial"
+	"canvas-demo/internal/canvas/svg"
 )
 
+// exportPadding 匯出圖片時內容四周保留的空白
+const exportPadding = 10.0
+
+// Modifiers 表示滑鼠事件發生時按住的修飾鍵
+type Modifiers struct {
+	Shift bool
+	Alt   bool
+}
+
+// boxShape 是以拖曳兩個對角點建立的形狀（矩形、橢圓）
+type boxShape interface {
+	shape.Shape
+	SetCorners(a, b shape.Point)
+}
+
 // CanvasManager 處理所有 Canvas 相關操作
 type CanvasManager struct {
-	canvas        js.Value
-	ctx           js.Value
-	width         float64
-	height        float64
-	shapes        []shape.Shape
-	currentLine   *shape.Line
-	currentText   *shape.Text
-	selectedShape shape.Shape
-	isDragging    bool
-	isScaling     bool
-	activeControl shape.ControlPoint
-	lastX         float64
-	lastY         float64
-	scaleCenter   shape.Point
-	currentTool   string // 當前選擇的工具：line, text
+	canvas            js.Value
+	renderer          render.Renderer
+	strokeStyle       string
+	lineWidth         float64
+	fillStyle         string // 矩形、橢圓的填滿顏色，空字串表示不填滿
+	strokeShapes      bool   // 矩形、橢圓是否描邊
+	width             float64
+	height            float64
+	layers            []*layer.Layer // 由下往上的繪製順序
+	activeLayer       *layer.Layer   // 新形狀加入的圖層
+	nextLayerID       int
+	index             *spatial.Index // 所有圖層中形狀的空間索引
+	currentLine       *shape.Line
+	currentText       *shape.Text
+	currentBox        boxShape         // 正在拖曳建立的矩形或橢圓
+	currentConn       *shape.Connector // 正在拖曳建立的直線或箭頭
+	anchor            shape.Point      // 拖曳建立或移動形狀時的起點
+	selection         []shape.Shape    // 選取的形狀，依選取的先後順序
+	isDragging        bool
+	dragOrigin        shape.Point    // 開始移動時選取範圍的左上角
+	guides            []guide        // 移動時顯示的對齊參考線
+	damage            []shape.Bounds // 上次繪製後有變更、需要重繪的範圍
+	overlay           []shape.Bounds // 上次繪製時控制點、參考線等覆蓋內容的範圍
+	strokePainted     int            // 繪製中的筆畫已經畫在畫面上的點數
+	frame             frameLoop      // 下一個影格要繪製的內容與繪製效能的統計
+	isSelecting       bool           // 正在拖曳框選範圍
+	marqueeEnd        shape.Point    // 框選範圍中與 anchor 相對的角
+	marqueeBase       []shape.Shape  // 按住 Shift 開始框選時原本的選取
+	isScaling         bool
+	isReshaping       bool // 正在拖曳直線的端點
+	isRotating        bool // 正在拖曳旋轉控制點
+	isErasing         bool // 正在使用橡皮擦
+	activeControl     shape.ControlPoint
+	lastX             float64
+	lastY             float64
+	scaleCenter       shape.Point
+	rotatePivot       shape.Point  // 旋轉中心
+	rotateStart       float64      // 開始旋轉時滑鼠相對於旋轉中心的角度
+	rotateBase        float64      // 開始旋轉時形狀的角度
+	rotateApplied     float64      // 這次拖曳已經套用的旋轉量
+	currentTool       string       // 當前選擇的工具：select, line, text, rect, ellipse, straight, arrow, eraser
+	arrowStart        shape.Marker // 箭頭工具的起點標記
+	arrowEnd          shape.Marker // 箭頭工具的終點標記
+	modifiers         Modifiers
+	history           *History
+	gesture           int         // 每次按下滑鼠遞增，用於合併同一次拖曳的命令
+	editingText       *shape.Text // 正在編輯的文字物件
+	editBefore        string      // 開始編輯前的文字內容
+	view              viewport    // 畫面的縮放與平移
+	isPanning         bool        // 正在拖曳移動畫面
+	panLast           shape.Point // 移動畫面時指標最後的螢幕位置
+	spacePressed      bool        // 是否按住空白鍵
+	background        string      // 背景圖樣：none、dots 或 lines
+	grid              grid.Grid   // 格線與對齊格線的設定
+	showMinimap       bool        // 是否顯示小地圖
+	pointer           Pointer     // 正在拖曳的指標最後一筆輸入
+	pointerActive     bool        // 是否有指標按下
+	hover             shape.Point // 滑鼠最後在畫布上的位置
+	hovering          bool        // 滑鼠是否在畫布上
+	pasteData         string      // 最近一次複製或貼上的剪貼簿內容
+	pasteCount        int         // 同一份內容連續貼上的次數
+	eraserRadius      float64
+	eraserMode        string          // split 或 stroke
+	simplifyTolerance float64         // 完成筆畫時簡化的容差，0 表示不簡化
+	smoothing         shape.Smoothing // 新筆畫的平滑方式
+	penStyle          shape.PenStyle  // 觸控筆筆畫的寬度曲線
 }
 
 // NewCanvasManager 創建新的 Canvas 管理器
@@ -33,15 +108,37 @@ func NewCanvasManager(canvasID string) *CanvasManager {
 	doc := js.Global().Get("document")
 	canvas := doc.Call("getElementById", canvasID)
 	ctx := canvas.Call("getContext", "2d")
-
-	return &CanvasManager{
-		canvas:      canvas,
-		ctx:         ctx,
-		width:       canvas.Get("width").Float(),
-		height:      canvas.Get("height").Float(),
-		shapes:      make([]shape.Shape, 0),
-		currentTool: "line", // 預設工具為畫線
+	first := layer.New(1, layer.DefaultName(1))
+
+	cm := &CanvasManager{
+		canvas:            canvas,
+		renderer:          render.NewCanvas2D(ctx),
+		strokeStyle:       "#000000",
+		lineWidth:         1,
+		strokeShapes:      true,
+		arrowStart:        shape.MarkerNone,
+		arrowEnd:          shape.MarkerArrow,
+		width:             canvas.Get("width").Float(),
+		height:            canvas.Get("height").Float(),
+		layers:            []*layer.Layer{first},
+		activeLayer:       first,
+		nextLayerID:       2,
+		index:             spatial.New(),
+		view:              viewport{scale: 1},
+		background:        backgroundDots,
+		grid:              grid.Default(),
+		showMinimap:       true,
+		currentTool:       "line", // 預設工具為畫線
+		history:           NewHistory(defaultHistoryLimit),
+		eraserRadius:      defaultEraserRadius,
+		eraserMode:        eraserSplit,
+		simplifyTolerance: defaultSimplifyTolerance,
+		smoothing:         shape.SmoothCatmullRom,
+		penStyle:          shape.DefaultPenStyle,
 	}
+	cm.history.OnChange(cm.indexCommand)
+	cm.frame.callback = js.FuncOf(cm.renderFrame)
+	return cm
 }
 
 // SetCurrentTool 設置當前工具
@@ -51,32 +148,92 @@ func (cm *CanvasManager) SetCurrentTool(tool string) {
 
 // Clear 清除整個畫布
 func (cm *CanvasManager) Clear() {
-	cm.ctx.Call("clearRect", 0, 0, cm.width, cm.height)
+	cm.renderer.ClearRect(0, 0, cm.width, cm.height)
 }
 
 // SetStrokeStyle 設置線條樣式
 func (cm *CanvasManager) SetStrokeStyle(color string) {
-	cm.ctx.Set("strokeStyle", color)
+	cm.strokeStyle = color
 }
 
 // SetLineWidth 設置線條寬度
 func (cm *CanvasManager) SetLineWidth(width float64) {
-	cm.ctx.Set("lineWidth", width)
+	cm.lineWidth = width
+}
+
+// SetFillStyle 設置矩形、橢圓的填滿顏色，空字串表示不填滿
+func (cm *CanvasManager) SetFillStyle(color string) {
+	cm.fillStyle = color
+}
+
+// SetStrokeEnabled 設置矩形、橢圓是否描邊
+func (cm *CanvasManager) SetStrokeEnabled(enabled bool) {
+	cm.strokeShapes = enabled
+}
+
+// SetArrowMarkers 設置箭頭工具兩端的標記：none, arrow, dot, bar
+func (cm *CanvasManager) SetArrowMarkers(start, end string) error {
+	startMarker, err := shape.ParseMarker(start)
+	if err != nil {
+		return err
+	}
+	endMarker, err := shape.ParseMarker(end)
+	if err != nil {
+		return err
+	}
+	cm.arrowStart = startMarker
+	cm.arrowEnd = endMarker
+	return nil
+}
+
+// PointerLeave 記錄滑鼠已離開畫布：之後的貼上改用偏移放置，並移除橡皮擦範圍的提示
+func (cm *CanvasManager) PointerLeave() {
+	cm.hovering = false
+	cm.repaint()
+}
+
+// SetModifiers 更新目前按住的修飾鍵
+func (cm *CanvasManager) SetModifiers(m Modifiers) {
+	cm.modifiers = m
 }
 
 // StartDrawing 開始繪圖
 func (cm *CanvasManager) StartDrawing(x, y float64) {
 	p := shape.Point{X: x, Y: y}
+	cm.hover, cm.hovering = p, true
+	cm.gesture++
+
+	// 橡皮擦不會選取形狀
+	if cm.currentTool == "eraser" {
+		cm.startErasing(p)
+		return
+	}
 
 	// 如果有選中的形狀，檢查是否點擊到控制點
-	if cm.selectedShape != nil {
-		controlPoint := cm.selectedShape.HitControl(p)
+	if len(cm.selection) > 0 {
+		controlPoint := cm.hitSelectionControl(p)
+		if controlPoint == shape.StartPoint || controlPoint == shape.EndPoint {
+			cm.isReshaping = true
+			cm.activeControl = controlPoint
+			return
+		}
+		if controlPoint == shape.Rotate {
+			cm.isRotating = true
+			cm.activeControl = controlPoint
+			cm.rotatePivot = cm.selectionControlPosition(shape.None)
+			cm.rotateStart = math.Atan2(y-cm.rotatePivot.Y, x-cm.rotatePivot.X)
+			cm.rotateBase = cm.selectionRotation()
+			cm.rotateApplied = 0
+			return
+		}
 		if controlPoint != shape.None {
 			cm.isScaling = true
 			cm.activeControl = controlPoint
 			cm.scaleCenter = cm.getScaleCenter(controlPoint)
-			cm.lastX = x
-			cm.lastY = y
+			// 從控制點本身的位置開始計算，對齊格線時拖曳的角會落在格點上
+			start := cm.selectionControlPosition(controlPoint)
+			cm.lastX = start.X
+			cm.lastY = start.Y
 			return
 		}
 	}
@@ -84,44 +241,72 @@ func (cm *CanvasManager) StartDrawing(x, y float64) {
 	// 檢查是否點擊到現有形狀
 	clickedShape := cm.findShapeAt(p)
 	if clickedShape != nil {
-		// 如果點擊到的是當前選中的文字物件，開始編輯
-		if textObj, ok := clickedShape.(*shape.Text); ok && clickedShape == cm.selectedShape {
-			textObj.StartEditing(cm.canvas.Call("getBoundingClientRect"))
+		// 如果點擊到的是當前唯一選中的文字物件，開始編輯
+		if textObj, ok := clickedShape.(*shape.Text); ok && !cm.modifiers.Shift && clickedShape == cm.singleSelection() {
+			cm.startTextEdit(textObj)
 			return
 		}
 
 		// 如果之前有選中的文字物件，停止編輯
-		if cm.selectedShape != nil {
-			if textObj, ok := cm.selectedShape.(*shape.Text); ok {
-				textObj.StopEditing()
+		cm.stopTextEdit()
+
+		switch {
+		case cm.modifiers.Shift:
+			// 按住 Shift 時切換該形狀是否在選取中
+			cm.toggleSelected(clickedShape)
+			if !cm.isSelected(clickedShape) {
+				return
 			}
+		case !cm.isSelected(clickedShape):
+			// 點擊未選取的形狀時只選取它；點擊已選取的形狀時保留選取以便一起移動
+			cm.setSelectedShape(clickedShape)
 		}
-
-		// 設置新的選中物件
-		cm.setSelectedShape(clickedShape)
 		cm.isDragging = true
-		cm.lastX = x
-		cm.lastY = y
+		cm.anchor = p
+		b := cm.selectionBounds()
+		cm.dragOrigin = shape.Point{X: b.X, Y: b.Y}
 		return
 	}
 
-	// 如果沒有點擊到形狀，取消當前選中
-	if cm.selectedShape != nil {
-		if textObj, ok := cm.selectedShape.(*shape.Text); ok {
-			textObj.StopEditing()
-		}
+	// 如果沒有點擊到形狀，取消當前選中（按住 Shift 框選時保留）
+	cm.stopTextEdit()
+	if !(cm.currentTool == "select" && cm.modifiers.Shift) {
 		cm.setSelectedShape(nil)
 	}
 
+	// 作用中的圖層被隱藏或鎖定時不能新增形狀
+	if cm.currentTool != "select" && !cm.activeLayer.Editable() {
+		return
+	}
+
+	// 建立形狀的起點對齊格線，手繪線段與框選不對齊
+	if cm.currentTool != "select" && cm.currentTool != "line" {
+		p = cm.snapPoint(p)
+	}
+
 	// 根據當前工具開始相應的操作
 	switch cm.currentTool {
+	case "select":
+		cm.isSelecting = true
+		cm.anchor = p
+		cm.marqueeEnd = p
+		cm.marqueeBase = append([]shape.Shape(nil), cm.selection...)
 	case "line":
 		// 開始新的線段
 		cm.currentLine = shape.NewLine(shape.Style{
-			StrokeStyle: cm.ctx.Get("strokeStyle").String(),
-			LineWidth:   cm.ctx.Get("lineWidth").Float(),
+			StrokeStyle: cm.strokeStyle,
+			LineWidth:   cm.lineWidth,
 		})
-		cm.currentLine.AddPoint(p)
+		cm.currentLine.Smoothing = cm.smoothing
+		// 觸控筆的筆畫依筆壓改變寬度
+		if cm.pointer.Type == "pen" {
+			cm.currentLine.Pen = cm.penStyle
+			cm.currentLine.Style.LineWidth = cm.penStyle.MaxWidth
+			cm.currentLine.AddPressurePoint(p, cm.pointer.Pressure)
+		} else {
+			cm.currentLine.AddPoint(p)
+		}
+		cm.strokePainted = 1
 	case "text":
 		// 創建新的文字
 		newText := shape.NewText(p, shape.TextStyle{
@@ -129,47 +314,64 @@ func (cm *CanvasManager) StartDrawing(x, y float64) {
 			FillStyle: "#000000",
 			Size:      20,
 		})
-		cm.shapes = append(cm.shapes, newText)
+		cm.addShape(newText)
 		cm.setSelectedShape(newText)
-		newText.StartEditing(cm.canvas.Call("getBoundingClientRect"))
+		cm.startTextEdit(newText)
+	case "rect":
+		cm.currentBox = shape.NewRect(cm.boxStyle())
+		cm.anchor = p
+		cm.currentBox.SetCorners(p, p)
+	case "ellipse":
+		cm.currentBox = shape.NewEllipse(cm.boxStyle())
+		cm.anchor = p
+		cm.currentBox.SetCorners(p, p)
+	case "straight", "arrow":
+		style := shape.Style{StrokeStyle: cm.strokeStyle, LineWidth: cm.lineWidth}
+		if cm.currentTool == "arrow" {
+			cm.currentConn = shape.NewConnector(style, cm.arrowStart, cm.arrowEnd)
+		} else {
+			cm.currentConn = shape.NewConnector(style, shape.MarkerNone, shape.MarkerNone)
+		}
+		cm.anchor = p
+		cm.currentConn.SetEndpoints(p, p)
+	}
+}
+
+// boxStyle 回傳新矩形、橢圓使用的樣式
+func (cm *CanvasManager) boxStyle() shape.Style {
+	style := shape.Style{FillStyle: cm.fillStyle}
+	// 沒有填滿時一定要描邊，否則形狀會看不見
+	if cm.strokeShapes || cm.fillStyle == "" {
+		style.StrokeStyle = cm.strokeStyle
+		style.LineWidth = cm.lineWidth
 	}
+	return style
 }
 
 // Draw 繪製
 func (cm *CanvasManager) Draw(x, y float64) {
-	if cm.isScaling && cm.selectedShape != nil {
+	cm.hover, cm.hovering = shape.Point{X: x, Y: y}, true
+
+	if cm.isErasing {
+		cm.eraseTo(shape.Point{X: x, Y: y})
+		return
+	}
+	if cm.currentTool == "eraser" {
+		// 更新橡皮擦範圍的位置
+		cm.repaint()
+		return
+	}
+
+	if cm.isScaling && len(cm.selection) > 0 {
 		// 處理縮放
-		// 計算主要縮放方向
-		var scale float64
-		switch cm.activeControl {
-		case shape.TopLeft:
-			// 使用對角線距離變化來計算縮放比例
-			currentDist := math.Sqrt(math.Pow(x-cm.scaleCenter.X, 2) + math.Pow(y-cm.scaleCenter.Y, 2))
-			originalDist := math.Sqrt(math.Pow(cm.lastX-cm.scaleCenter.X, 2) + math.Pow(cm.lastY-cm.scaleCenter.Y, 2))
-			scale = currentDist / originalDist
-			// 當距離增加時應該縮小，反之應該放大
-			scale = 1 / scale
-		case shape.TopRight:
-			currentDist := math.Sqrt(math.Pow(x-cm.scaleCenter.X, 2) + math.Pow(y-cm.scaleCenter.Y, 2))
-			originalDist := math.Sqrt(math.Pow(cm.lastX-cm.scaleCenter.X, 2) + math.Pow(cm.lastY-cm.scaleCenter.Y, 2))
-			scale = currentDist / originalDist
-			// 當距離增加時應該放大
-			if x < cm.scaleCenter.X {
-				scale = 1 / scale
-			}
-		case shape.BottomLeft:
-			currentDist := math.Sqrt(math.Pow(x-cm.scaleCenter.X, 2) + math.Pow(y-cm.scaleCenter.Y, 2))
-			originalDist := math.Sqrt(math.Pow(cm.lastX-cm.scaleCenter.X, 2) + math.Pow(cm.lastY-cm.scaleCenter.Y, 2))
-			scale = currentDist / originalDist
-			// 當距離增加時應該放大
-			if x > cm.scaleCenter.X {
-				scale = 1 / scale
-			}
-		case shape.BottomRight:
-			currentDist := math.Sqrt(math.Pow(x-cm.scaleCenter.X, 2) + math.Pow(y-cm.scaleCenter.Y, 2))
-			originalDist := math.Sqrt(math.Pow(cm.lastX-cm.scaleCenter.X, 2) + math.Pow(cm.lastY-cm.scaleCenter.Y, 2))
-			scale = currentDist / originalDist
+		// 以控制點到對角（縮放中心）的距離變化計算縮放比例，旋轉後的形狀同樣適用
+		p := cm.snapPoint(shape.Point{X: x, Y: y})
+		currentDist := math.Hypot(p.X-cm.scaleCenter.X, p.Y-cm.scaleCenter.Y)
+		originalDist := math.Hypot(cm.lastX-cm.scaleCenter.X, cm.lastY-cm.scaleCenter.Y)
+		if originalDist == 0 {
+			return
 		}
+		scale := currentDist / originalDist
 
 		// 限制最小縮放比例
 		const minScale = 0.1
@@ -178,161 +380,544 @@ func (cm *CanvasManager) Draw(x, y float64) {
 		}
 
 		// 使用相同的縮放比例進行等比例縮放
-		cm.selectedShape.Scale(scale, scale, cm.scaleCenter)
-		cm.lastX = x
-		cm.lastY = y
-		cm.redraw()
+		cm.history.Execute(&scaleCommand{
+			shapes:  cm.selectedShapes(),
+			sx:      scale,
+			sy:      scale,
+			center:  cm.scaleCenter,
+			gesture: cm.gesture,
+		})
+		cm.lastX = p.X
+		cm.lastY = p.Y
+		cm.repaint()
 		return
 	}
 
-	if cm.isDragging && cm.selectedShape != nil {
-		// 移動選中的形狀
-		dx := x - cm.lastX
-		dy := y - cm.lastY
-		cm.selectedShape.Move(dx, dy)
-		cm.lastX = x
-		cm.lastY = y
-		cm.redraw()
+	if cm.isRotating && len(cm.selection) > 0 {
+		current := math.Atan2(y-cm.rotatePivot.Y, x-cm.rotatePivot.X)
+		target := cm.rotateBase + current - cm.rotateStart
+		// 按住 Shift 時角度對齊 15°
+		if cm.modifiers.Shift {
+			target = math.Round(target/angleSnapStep) * angleSnapStep
+		}
+		delta := target - cm.rotateBase - cm.rotateApplied
+		if delta != 0 {
+			cm.history.Execute(&rotateCommand{
+				shapes:  cm.selectedShapes(),
+				angle:   delta,
+				pivot:   cm.rotatePivot,
+				gesture: cm.gesture,
+			})
+			cm.rotateApplied += delta
+		}
+		cm.repaint()
+		return
+	}
+
+	if cm.isReshaping {
+		if conn, ok := cm.singleSelection().(*shape.Connector); ok {
+			p := shape.Point{X: x, Y: y}
+			// 按住 Shift 時以另一端為中心，角度對齊 15°
+			if cm.modifiers.Shift {
+				other := shape.EndPoint
+				if cm.activeControl == shape.EndPoint {
+					other = shape.StartPoint
+				}
+				p = snapAngle(conn.Endpoint(other), p, angleSnapStep)
+			} else {
+				p = cm.snapPoint(p)
+			}
+			cm.history.Execute(&endpointCommand{
+				conn:    conn,
+				which:   cm.activeControl,
+				from:    conn.Endpoint(cm.activeControl),
+				to:      p,
+				gesture: cm.gesture,
+			})
+			cm.repaint()
+		}
+		return
+	}
+
+	if cm.isSelecting {
+		cm.marqueeEnd = shape.Point{X: x, Y: y}
+		cm.selectInMarquee()
+		return
+	}
+
+	if cm.isDragging && len(cm.selection) > 0 {
+		// 移動所有選中的形狀，以開始拖曳時的位置計算，讓選取範圍對齊其他形狀或格線
+		b := cm.selectionBounds()
+		target := cm.snapMove(shape.Bounds{
+			X:      cm.dragOrigin.X + x - cm.anchor.X,
+			Y:      cm.dragOrigin.Y + y - cm.anchor.Y,
+			Width:  b.Width,
+			Height: b.Height,
+		})
+		if dx, dy := target.X-b.X, target.Y-b.Y; dx != 0 || dy != 0 {
+			cm.history.Execute(&moveCommand{
+				shapes:  cm.selectedShapes(),
+				dx:      dx,
+				dy:      dy,
+				gesture: cm.gesture,
+			})
+		}
+		cm.repaint()
 		return
 	}
 
 	if cm.currentLine != nil {
-		// 繼續繪製當前線段
-		cm.currentLine.AddPoint(shape.Point{X: x, Y: y})
-		cm.redraw()
+		// 繼續繪製當前線段，只畫出新增的部分
+		cm.addStrokePoint(shape.Point{X: x, Y: y})
+		cm.paintStroke()
+		return
+	}
+
+	if cm.currentBox != nil {
+		corner := cm.snapPoint(shape.Point{X: x, Y: y})
+		// 按住 Shift 時限制為正方形或圓形
+		if cm.modifiers.Shift {
+			corner = constrainSquare(cm.anchor, corner)
+		}
+		cm.currentBox.SetCorners(cm.anchor, corner)
+		cm.repaint()
+		return
+	}
+
+	if cm.currentConn != nil {
+		end := shape.Point{X: x, Y: y}
+		// 按住 Shift 時角度對齊 15°
+		if cm.modifiers.Shift {
+			end = snapAngle(cm.anchor, end, angleSnapStep)
+		} else {
+			end = cm.snapPoint(end)
+		}
+		cm.currentConn.SetEndpoints(cm.anchor, end)
+		cm.repaint()
+	}
+}
+
+// angleSnapStep 按住 Shift 時角度對齊的間隔（15°）
+const angleSnapStep = math.Pi / 12
+
+// snapAngle 保持 p 與 origin 的距離，將方向對齊到 step 的倍數
+func snapAngle(origin, p shape.Point, step float64) shape.Point {
+	dx := p.X - origin.X
+	dy := p.Y - origin.Y
+	length := math.Hypot(dx, dy)
+	angle := math.Round(math.Atan2(dy, dx)/step) * step
+	return shape.Point{
+		X: origin.X + length*math.Cos(angle),
+		Y: origin.Y + length*math.Sin(angle),
 	}
 }
 
-// StopDrawing 停止繪圖
+// constrainSquare 以較長的一邊為準，讓 anchor 到 p 的範圍成為正方形
+func constrainSquare(anchor, p shape.Point) shape.Point {
+	dx := p.X - anchor.X
+	dy := p.Y - anchor.Y
+	side := math.Max(math.Abs(dx), math.Abs(dy))
+	return shape.Point{
+		X: anchor.X + math.Copysign(side, dx),
+		Y: anchor.Y + math.Copysign(side, dy),
+	}
+}
+
+// StopDrawing 停止繪圖。拖曳時只重繪有變更的範圍，結束時重繪整個畫面以更新小地圖
 func (cm *CanvasManager) StopDrawing() {
 	if cm.isScaling {
 		cm.isScaling = false
 		cm.activeControl = shape.None
+		cm.redraw()
+		return
+	}
+
+	if cm.isErasing {
+		cm.isErasing = false
+		cm.redraw()
+		return
+	}
+
+	if cm.isRotating {
+		cm.isRotating = false
+		cm.activeControl = shape.None
+		cm.redraw()
+		return
+	}
+
+	if cm.isReshaping {
+		cm.isReshaping = false
+		cm.activeControl = shape.None
+		cm.redraw()
+		return
+	}
+
+	if cm.isSelecting {
+		cm.isSelecting = false
+		cm.marqueeBase = nil
+		cm.redraw()
 		return
 	}
 
 	if cm.isDragging {
 		cm.isDragging = false
+		cm.guides = nil
+		cm.redraw()
 		return
 	}
 
 	if cm.currentLine != nil {
-		cm.shapes = append(cm.shapes, cm.currentLine)
+		cm.currentLine.Simplify(cm.simplifyTolerance)
+		cm.addShape(cm.currentLine)
 		cm.currentLine = nil
+		// 繪製時只畫了直線段，以平滑後的完整筆畫重畫
+		cm.redraw()
+	}
+
+	if cm.currentBox != nil {
+		// 只是點一下而沒有拖曳時不建立形狀
+		if b := cm.currentBox.GetBounds(); b.Width > 0 || b.Height > 0 {
+			cm.addShape(cm.currentBox)
+		}
+		cm.currentBox = nil
+		cm.redraw()
+	}
+
+	if cm.currentConn != nil {
+		if cm.currentConn.Start != cm.currentConn.End {
+			cm.addShape(cm.currentConn)
+		}
+		cm.currentConn = nil
+		cm.redraw()
 	}
 }
 
 // DeleteSelected 刪除選中的形狀
 func (cm *CanvasManager) DeleteSelected() {
-	if cm.selectedShape == nil {
+	if len(cm.selection) == 0 {
+		return
+	}
+
+	cm.stopTextEdit()
+	cm.history.Execute(&deleteShapesCommand{cm: cm, shapes: cm.selectedShapes()})
+	cm.syncSelection()
+	cm.redraw()
+}
+
+// GroupSelected 將選取的形狀組成一個群組，至少需要選取兩個形狀
+func (cm *CanvasManager) GroupSelected() {
+	if len(cm.selection) < 2 {
 		return
 	}
 
-	// 從形狀列表中移除
-	for i, s := range cm.shapes {
-		if s == cm.selectedShape {
-			// 如果是文字物件，需要移除 HTML 元素
-			if textObj, ok := s.(*shape.Text); ok {
-				textObj.Delete()
+	cm.stopTextEdit()
+
+	// 子形狀保持原本的繪製順序
+	group := shape.NewGroup(cm.orderedSelection())
+	cm.history.Execute(&groupCommand{cm: cm, group: group})
+	cm.setSelectedShape(group)
+}
+
+// UngroupSelected 拆開選取中的群組，並選取拆出來的形狀
+func (cm *CanvasManager) UngroupSelected() {
+	var groups []*shape.Group
+	var children []shape.Shape
+	for _, s := range cm.selection {
+		if g, ok := s.(*shape.Group); ok {
+			groups = append(groups, g)
+			children = append(children, g.Children...)
+		}
+	}
+	if len(groups) == 0 {
+		return
+	}
+
+	cm.stopTextEdit()
+	cm.history.Execute(&ungroupCommand{cm: cm, groups: groups})
+	cm.setSelection(children)
+}
+
+// BringForward 將選取的形狀往上移一層
+func (cm *CanvasManager) BringForward() {
+	cm.reorder(func(order []shape.Shape) {
+		// 由上往下處理，讓相鄰的選取形狀一起移動
+		for i := len(order) - 2; i >= 0; i-- {
+			if cm.isSelected(order[i]) && !cm.isSelected(order[i+1]) {
+				order[i], order[i+1] = order[i+1], order[i]
 			}
-			cm.shapes = append(cm.shapes[:i], cm.shapes[i+1:]...)
-			break
 		}
+	})
+}
+
+// SendBackward 將選取的形狀往下移一層
+func (cm *CanvasManager) SendBackward() {
+	cm.reorder(func(order []shape.Shape) {
+		for i := 1; i < len(order); i++ {
+			if cm.isSelected(order[i]) && !cm.isSelected(order[i-1]) {
+				order[i], order[i-1] = order[i-1], order[i]
+			}
+		}
+	})
+}
+
+// BringToFront 將選取的形狀移到最上層，保持彼此之間的順序
+func (cm *CanvasManager) BringToFront() {
+	cm.reorder(func(order []shape.Shape) {
+		sort.SliceStable(order, func(i, j int) bool {
+			return !cm.isSelected(order[i]) && cm.isSelected(order[j])
+		})
+	})
+}
+
+// SendToBack 將選取的形狀移到最下層，保持彼此之間的順序
+func (cm *CanvasManager) SendToBack() {
+	cm.reorder(func(order []shape.Shape) {
+		sort.SliceStable(order, func(i, j int) bool {
+			return cm.isSelected(order[i]) && !cm.isSelected(order[j])
+		})
+	})
+}
+
+// reorder 以 arrange 調整每個含有選取形狀的圖層的順序，有改變時記錄為一個可復原的步驟
+func (cm *CanvasManager) reorder(arrange func(order []shape.Shape)) {
+	if len(cm.selection) == 0 {
+		return
 	}
 
-	cm.selectedShape = nil
+	cmd := &reorderCommand{}
+	for _, l := range cm.layers {
+		after := append([]shape.Shape(nil), l.Shapes...)
+		arrange(after)
+		if sameShapes(l.Shapes, after) {
+			continue
+		}
+		cmd.layers = append(cmd.layers, l)
+		cmd.before = append(cmd.before, append([]shape.Shape(nil), l.Shapes...))
+		cmd.after = append(cmd.after, after)
+	}
+	if len(cmd.layers) == 0 {
+		return
+	}
+
+	cm.history.Execute(cmd)
 	cm.redraw()
 }
 
-// setSelectedShape 設置選中的形狀
-func (cm *CanvasManager) setSelectedShape(s shape.Shape) {
-	// 取消之前選中形狀的選中狀態
-	if cm.selectedShape != nil {
-		switch v := cm.selectedShape.(type) {
-		case *shape.Line:
-			v.SetSelected(false)
-		case *shape.Text:
-			v.SetSelected(false)
-		}
+// Undo 復原上一個操作
+func (cm *CanvasManager) Undo() {
+	// 先結束文字編輯，讓未提交的修改成為可復原的一步
+	cm.stopTextEdit()
+	if cm.history.Undo() {
+		cm.syncSelection()
+		cm.redraw()
 	}
+}
 
-	cm.selectedShape = s
+// Redo 重做上一個被復原的操作
+func (cm *CanvasManager) Redo() {
+	cm.stopTextEdit()
+	if cm.history.Redo() {
+		cm.syncSelection()
+		cm.redraw()
+	}
+}
 
-	// 設置新形狀的選中狀態
-	if s != nil {
-		switch v := s.(type) {
-		case *shape.Line:
-			v.SetSelected(true)
-		case *shape.Text:
-			v.SetSelected(true)
-		}
+// SetHistoryLimit 設置可復原的步數上限，0 表示不限制
+func (cm *CanvasManager) SetHistoryLimit(limit int) {
+	cm.history.SetLimit(limit)
+}
+
+// SaveDocument 將目前所有形狀序列化為 JSON 文件
+func (cm *CanvasManager) SaveDocument() (string, error) {
+	// 先提交正在編輯的文字
+	cm.stopTextEdit()
+
+	g := cm.grid
+	data, err := document.Marshal(&document.Document{Layers: cm.layers, Grid: &g})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// LoadDocument 載入 JSON 文件並取代目前所有形狀，文件不正確時保持原狀
+func (cm *CanvasManager) LoadDocument(data string) error {
+	doc, err := document.Unmarshal([]byte(data))
+	if err != nil {
+		return err
 	}
 
+	cm.stopTextEdit()
+	cm.setSelectedShape(nil)
+
+	// 移除舊形狀（包含文字的 HTML 輸入框）
+	for _, s := range layer.Shapes(cm.layers) {
+		s.Delete()
+	}
+
+	// 文件中的圖層 ID 從 1 開始編號，最上層的圖層設為作用中
+	cm.layers = doc.Layers
+	cm.activeLayer = doc.Layers[len(doc.Layers)-1]
+	cm.nextLayerID = len(doc.Layers) + 1
+	cm.rebuildIndex()
+	// 沒有儲存格線設定的文件使用預設值
+	cm.grid = grid.Default()
+	if doc.Grid != nil {
+		cm.grid = *doc.Grid
+	}
+	cm.currentLine = nil
+	cm.currentBox = nil
+	cm.currentConn = nil
+	cm.history.Clear()
 	cm.redraw()
+	return nil
 }
 
-// getScaleCenter 根據控制點獲取縮放中心
-func (cm *CanvasManager) getScaleCenter(cp shape.ControlPoint) shape.Point {
-	bounds := cm.selectedShape.GetBounds()
-	switch cp {
-	case shape.TopLeft:
-		return shape.Point{
-			X: bounds.X + bounds.Width,
-			Y: bounds.Y + bounds.Height,
-		}
-	case shape.TopRight:
-		return shape.Point{
-			X: bounds.X,
-			Y: bounds.Y + bounds.Height,
-		}
-	case shape.BottomLeft:
-		return shape.Point{
-			X: bounds.X + bounds.Width,
-			Y: bounds.Y,
-		}
-	case shape.BottomRight:
-		return shape.Point{
-			X: bounds.X,
-			Y: bounds.Y,
-		}
-	default:
-		return shape.Point{
-			X: bounds.X + bounds.Width/2,
-			Y: bounds.Y + bounds.Height/2,
-		}
+// ExportSVG 將目前所有形狀匯出為 SVG 字串
+func (cm *CanvasManager) ExportSVG() (string, error) {
+	cm.stopTextEdit()
+
+	data, err := svg.MarshalLayers(cm.layers, cm.exportBounds())
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ExportPNG 以軟體繪製目前所有形狀並編碼為 PNG
+func (cm *CanvasManager) ExportPNG() ([]byte, error) {
+	cm.stopTextEdit()
+
+	var buf bytes.Buffer
+	if err := raster.EncodePNGLayers(&buf, cm.layers, cm.exportBounds()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// exportBounds 計算匯出範圍：有內容時包住可見圖層的所有形狀，否則使用目前畫面的範圍
+func (cm *CanvasManager) exportBounds() shape.Bounds {
+	shapes := layer.VisibleShapes(cm.layers)
+	if len(shapes) == 0 {
+		return cm.visibleArea()
+	}
+	return shape.UnionBounds(shapes).Inflate(exportPadding)
+}
+
+// startTextEdit 開始編輯文字並記錄原始內容
+func (cm *CanvasManager) startTextEdit(t *shape.Text) {
+	if cm.editingText == t {
+		return
+	}
+	cm.stopTextEdit()
+	cm.editingText = t
+	cm.editBefore = t.Content
+	origin := cm.canvasOrigin()
+	t.StartEditing(shape.Point{X: origin.X + cm.view.x, Y: origin.Y + cm.view.y}, cm.view.scale)
+}
+
+// stopTextEdit 結束文字編輯，內容有變更時記錄到歷史中
+func (cm *CanvasManager) stopTextEdit() {
+	t := cm.editingText
+	if t == nil {
+		return
+	}
+	t.StopEditing()
+	cm.editingText = nil
+	if t.Content != cm.editBefore {
+		cm.history.Push(&editTextCommand{text: t, before: cm.editBefore, after: t.Content})
 	}
 }
 
-// GetMousePosition 獲取滑鼠在 Canvas 上的位置
+// GetMousePosition 獲取滑鼠或指標事件在畫布座標中的位置（已換算畫面的縮放與平移）
 func (cm *CanvasManager) GetMousePosition(event js.Value) (float64, float64) {
+	p := cm.view.toWorld(cm.ScreenPosition(event))
+	return p.X, p.Y
+}
+
+// ScreenPosition 獲取滑鼠或指標事件在 Canvas 元素上的螢幕位置
+func (cm *CanvasManager) ScreenPosition(event js.Value) shape.Point {
 	rect := cm.canvas.Call("getBoundingClientRect")
-	x := event.Get("clientX").Float() - rect.Get("left").Float()
-	y := event.Get("clientY").Float() - rect.Get("top").Float()
-	return x, y
+	return shape.Point{
+		X: event.Get("clientX").Float() - rect.Get("left").Float(),
+		Y: event.Get("clientY").Float() - rect.Get("top").Float(),
+	}
 }
 
-// findShapeAt 找到指定位置的形狀
+// canvasOrigin 獲取 Canvas 左上角在視窗中的位置
+func (cm *CanvasManager) canvasOrigin() shape.Point {
+	rect := cm.canvas.Call("getBoundingClientRect")
+	return shape.Point{X: rect.Get("left").Float(), Y: rect.Get("top").Float()}
+}
+
+// findShapeAt 找到指定位置的形狀，略過隱藏與鎖定的圖層
 func (cm *CanvasManager) findShapeAt(p shape.Point) shape.Shape {
-	// 從後往前檢查，這樣可以選中最上層的形狀
-	for i := len(cm.shapes) - 1; i >= 0; i-- {
-		if cm.shapes[i].Contains(p) {
-			return cm.shapes[i]
-		}
-	}
-	return nil
+	// 只檢查空間索引中邊界靠近該點的形狀，從最上層開始
+	return cm.index.Pick(cm.editableShapes(), p, cm.view.screenLength(pickMargin), cm.view.scale)
 }
 
-// redraw 重新繪製所有形狀
+// redraw 標記需要重繪整個畫面，在下一個影格繪製
 func (cm *CanvasManager) redraw() {
+	cm.frame.redraw = true
+	cm.requestFrame()
+}
+
+// drawAll 重新繪製整個畫面
+func (cm *CanvasManager) drawAll() {
+	cm.frame.fullRedraws++
+	cm.damage, cm.overlay = nil, cm.overlayBounds()
 	cm.Clear()
 
-	// 繪製所有已完成的形狀
-	for _, s := range cm.shapes {
-		s.Draw(cm.ctx)
+	cm.renderer.Save()
+	cm.drawScene([]shape.Bounds{cm.visibleArea()})
+	cm.renderer.Restore()
+	if cm.currentLine != nil {
+		cm.strokePainted = len(cm.currentLine.Points)
+	}
+
+	// 小地圖以螢幕座標畫在最上層
+	cm.drawMinimap()
+}
+
+// drawScene 以畫布座標繪製範圍 areas 內的背景、形狀與覆蓋內容，呼叫前需要保存繪圖狀態
+func (cm *CanvasManager) drawScene(areas []shape.Bounds) {
+	cm.renderer.Translate(cm.view.x, cm.view.y)
+	cm.renderer.Scale(cm.view.scale, cm.view.scale)
+
+	background := areas[0]
+	for _, a := range areas[1:] {
+		background = background.Union(a)
+	}
+	cm.drawBackground(background)
+
+	// 依圖層順序繪製範圍內已完成的形狀
+	visible := make(map[shape.Shape]bool)
+	for _, a := range areas {
+		for s := range cm.shapesIn(a.Inflate(cullMargin)) {
+			visible[s] = true
+		}
+	}
+	for _, l := range cm.layers {
+		l.DrawOnly(cm.renderer, visible)
 	}
 
 	// 繪製當前正在繪製的線段
 	if cm.currentLine != nil {
-		cm.currentLine.Draw(cm.ctx)
+		cm.currentLine.Draw(cm.renderer)
+	}
+
+	// 繪製正在拖曳建立的矩形或橢圓
+	if cm.currentBox != nil {
+		cm.currentBox.Draw(cm.renderer)
 	}
+
+	// 繪製正在拖曳建立的直線
+	if cm.currentConn != nil {
+		cm.currentConn.Draw(cm.renderer)
+	}
+
+	// 控制點與框選範圍畫在所有形狀之上
+	cm.drawSelection()
+	cm.drawGuides()
+	cm.drawEraser()
 }
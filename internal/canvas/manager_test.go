@@ -0,0 +1,64 @@
+//go:build js && wasm
+
+package canvas
+
+import (
+	"testing"
+
+	"canvas-demo/internal/canvas/layer"
+	"canvas-demo/internal/canvas/render"
+	"canvas-demo/internal/canvas/shape"
+	"canvas-demo/internal/canvas/spatial"
+)
+
+// newTestManager 建立不需要 DOM 的管理器，以 Recorder 繪製
+func newTestManager() *CanvasManager {
+	first := layer.New(1, layer.DefaultName(1))
+	cm := &CanvasManager{
+		renderer:     render.NewRecorder(),
+		width:        800,
+		height:       600,
+		layers:       []*layer.Layer{first},
+		activeLayer:  first,
+		nextLayerID:  2,
+		index:        spatial.New(),
+		view:         viewport{scale: 1},
+		currentTool:  "line",
+		history:      NewHistory(defaultHistoryLimit),
+		eraserRadius: defaultEraserRadius,
+		eraserMode:   eraserSplit,
+	}
+	cm.history.OnChange(cm.indexCommand)
+	// 測試中沒有 requestAnimationFrame，不排程繪製
+	cm.frame.requested = true
+	return cm
+}
+
+func TestEraserDragsAreSeparateUndoSteps(t *testing.T) {
+	cm := newTestManager()
+	line := shape.NewLine(shape.Style{StrokeStyle: "#000000", LineWidth: 2})
+	line.AddPoint(shape.Point{X: 0, Y: 50})
+	line.AddPoint(shape.Point{X: 300, Y: 50})
+	cm.insertShape(cm.activeLayer, 0, line)
+	cm.currentTool = "eraser"
+
+	for _, x := range []float64{100, 200} {
+		cm.StartDrawing(x, 50)
+		cm.StopDrawing()
+	}
+	if got := len(cm.activeLayer.Shapes); got != 3 {
+		t.Fatalf("after two erasures got %d pieces, want 3", got)
+	}
+
+	cm.Undo()
+	if got := len(cm.activeLayer.Shapes); got != 2 {
+		t.Fatalf("after one undo got %d pieces, want 2", got)
+	}
+	cm.Undo()
+	if got := cm.activeLayer.Shapes; len(got) != 1 || got[0] != line {
+		t.Fatalf("after two undos got %v, want the original line", got)
+	}
+	if cm.history.CanUndo() {
+		t.Error("more undo steps than eraser drags")
+	}
+}
@@ -0,0 +1,117 @@
+//go:build js && wasm
+
+package canvas
+
+import (
+	"syscall/js"
+
+	"canvas-demo/internal/canvas/shape"
+)
+
+// Pointer 是一筆 Pointer Event 的輸入資料
+type Pointer struct {
+	ID       int
+	Type     string      // mouse、pen 或 touch
+	X, Y     float64     // 畫布座標中的位置
+	Screen   shape.Point // Canvas 元素上的螢幕位置
+	Button   int         // 按下的按鈕：0 為主要按鈕，1 為中鍵
+	Pressure float64     // 0 到 1，不支援壓力的裝置按下時為 0.5
+}
+
+// ReadPointer 讀取 Pointer Event 的位置、種類與壓力
+func (cm *CanvasManager) ReadPointer(event js.Value) Pointer {
+	screen := cm.ScreenPosition(event)
+	world := cm.view.toWorld(screen)
+	return Pointer{
+		ID:       event.Get("pointerId").Int(),
+		Type:     event.Get("pointerType").String(),
+		X:        world.X,
+		Y:        world.Y,
+		Screen:   screen,
+		Button:   event.Get("button").Int(),
+		Pressure: event.Get("pressure").Float(),
+	}
+}
+
+// CoalescedPointers 讀取瀏覽器合併到這次 pointermove 中的所有輸入，
+// 不支援 getCoalescedEvents 時只回傳事件本身
+func (cm *CanvasManager) CoalescedPointers(event js.Value) []Pointer {
+	if event.Get("getCoalescedEvents").Type() == js.TypeFunction {
+		events := event.Call("getCoalescedEvents")
+		if n := events.Length(); n > 0 {
+			pointers := make([]Pointer, n)
+			for i := range pointers {
+				pointers[i] = cm.ReadPointer(events.Index(i))
+			}
+			return pointers
+		}
+	}
+	return []Pointer{cm.ReadPointer(event)}
+}
+
+// PointerDown 開始一次拖曳並捕捉指標，拖曳期間忽略其他指標（例如第二根手指）。
+// 按中鍵或按住空白鍵時拖曳會移動畫面
+func (cm *CanvasManager) PointerDown(p Pointer) {
+	if cm.pointerActive {
+		return
+	}
+	cm.pointerActive = true
+	cm.pointer = p
+	cm.canvas.Call("setPointerCapture", p.ID)
+	if p.Button == 1 || cm.spacePressed {
+		cm.startPanning(p.Screen)
+		return
+	}
+	cm.StartDrawing(p.X, p.Y)
+}
+
+// PointerMove 處理移動。拖曳時只接受按下的指標；畫筆會使用每一筆合併的輸入，
+// 其他操作只需要最後的位置
+func (cm *CanvasManager) PointerMove(pointers []Pointer) {
+	if len(pointers) == 0 {
+		return
+	}
+	last := pointers[len(pointers)-1]
+	if cm.pointerActive && last.ID != cm.pointer.ID {
+		return
+	}
+	// 觸控沒有懸停狀態，手指抬起後的移動不需要處理
+	if !cm.pointerActive && last.Type == "touch" {
+		return
+	}
+
+	if cm.isPanning {
+		cm.panTo(last.Screen)
+		return
+	}
+
+	if cm.currentLine != nil {
+		for _, p := range pointers[:len(pointers)-1] {
+			cm.pointer = p
+			cm.addStrokePoint(shape.Point{X: p.X, Y: p.Y})
+		}
+	}
+	cm.pointer = last
+	cm.Draw(last.X, last.Y)
+}
+
+// PointerUp 結束按下的指標的拖曳並釋放捕捉，pointercancel 也以相同方式處理
+func (cm *CanvasManager) PointerUp(p Pointer) {
+	if !cm.pointerActive || p.ID != cm.pointer.ID {
+		return
+	}
+	cm.pointerActive = false
+	if cm.canvas.Call("hasPointerCapture", p.ID).Bool() {
+		cm.canvas.Call("releasePointerCapture", p.ID)
+	}
+	if cm.isPanning {
+		cm.stopPanning()
+		return
+	}
+	cm.StopDrawing()
+
+	// 觸控抬起後不再有游標位置
+	if p.Type == "touch" {
+		cm.PointerLeave()
+	}
+}
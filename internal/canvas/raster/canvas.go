@@ -0,0 +1,616 @@
+// Package raster 以純 Go 實作 render.Renderer，將形狀繪製到 image.RGBA，
+// 讓 PNG 匯出可以在瀏覽器以外（例如命令列工具或伺服器）使用。
+package raster
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"regexp"
+	"strconv"
+
+	"canvas-demo/internal/canvas/render"
+)
+
+// 確保 Canvas 實作 Renderer
+var _ render.Renderer = (*Canvas)(nil)
+
+// point 是裝置座標（像素）中的點
+type point struct {
+	x, y float64
+}
+
+// subpath 是一段連續的折線
+type subpath struct {
+	points []point
+	closed bool
+}
+
+// state 是可被 Save/Restore 保存的繪圖狀態
+type state struct {
+	strokeColor color.NRGBA
+	fillColor   color.NRGBA
+	lineWidth   float64
+	globalAlpha float64
+	font        string
+	transform   matrix
+	clip        *mask // 裁剪範圍的覆蓋率，nil 表示不裁剪；Clip 會建立新的 mask，所以可以直接共用
+}
+
+// Canvas 是以軟體繪製的畫布
+type Canvas struct {
+	img   *image.RGBA
+	state state
+	stack []state
+	path  []subpath
+}
+
+// New 創建指定大小的透明畫布
+func New(width, height int) *Canvas {
+	return &Canvas{
+		img: image.NewRGBA(image.Rect(0, 0, width, height)),
+		state: state{
+			strokeColor: color.NRGBA{0, 0, 0, 255},
+			fillColor:   color.NRGBA{0, 0, 0, 255},
+			lineWidth:   1,
+			globalAlpha: 1,
+			font:        "10px sans-serif",
+			transform:   identity(),
+		},
+	}
+}
+
+// Image 回傳繪製結果
+func (c *Canvas) Image() *image.RGBA {
+	return c.img
+}
+
+// Translate 平移座標系
+func (c *Canvas) Translate(x, y float64) {
+	c.state.transform = c.state.transform.multiply(translation(x, y))
+}
+
+// Rotate 以弧度旋轉座標系
+func (c *Canvas) Rotate(angle float64) {
+	c.state.transform = c.state.transform.multiply(rotation(angle))
+}
+
+// Scale 縮放座標系
+func (c *Canvas) Scale(x, y float64) {
+	c.state.transform = c.state.transform.multiply(scaling(x, y))
+}
+
+// Save 保存目前的繪圖狀態
+func (c *Canvas) Save() {
+	c.stack = append(c.stack, c.state)
+}
+
+// Restore 恢復最近一次保存的繪圖狀態
+func (c *Canvas) Restore() {
+	if n := len(c.stack); n > 0 {
+		c.state = c.stack[n-1]
+		c.stack = c.stack[:n-1]
+	}
+}
+
+// SetStrokeStyle 設置線條顏色，無法解析的顏色會被忽略（與 Canvas 相同）
+func (c *Canvas) SetStrokeStyle(style string) {
+	if col, ok := parseColor(style); ok {
+		c.state.strokeColor = col
+	}
+}
+
+// SetFillStyle 設置填滿顏色，無法解析的顏色會被忽略
+func (c *Canvas) SetFillStyle(style string) {
+	if col, ok := parseColor(style); ok {
+		c.state.fillColor = col
+	}
+}
+
+// SetGlobalAlpha 設置之後繪製內容的整體透明度，超出 0 到 1 的值會被忽略
+func (c *Canvas) SetGlobalAlpha(alpha float64) {
+	if alpha >= 0 && alpha <= 1 {
+		c.state.globalAlpha = alpha
+	}
+}
+
+// SetLineWidth 設置線條寬度
+func (c *Canvas) SetLineWidth(width float64) {
+	if width > 0 {
+		c.state.lineWidth = width
+	}
+}
+
+// SetFont 設置字型
+func (c *Canvas) SetFont(font string) {
+	c.state.font = font
+}
+
+// BeginPath 開始新的路徑
+func (c *Canvas) BeginPath() {
+	c.path = c.path[:0]
+}
+
+// ClosePath 封閉目前的子路徑
+func (c *Canvas) ClosePath() {
+	n := len(c.path)
+	if n == 0 || len(c.path[n-1].points) == 0 {
+		return
+	}
+	c.path[n-1].closed = true
+	// 與 Canvas 相同，封閉後從起點開始新的子路徑
+	c.path = append(c.path, subpath{points: []point{c.path[n-1].points[0]}})
+}
+
+// MoveTo 開始新的子路徑
+func (c *Canvas) MoveTo(x, y float64) {
+	c.path = append(c.path, subpath{points: []point{c.state.transform.apply(x, y)}})
+}
+
+// LineTo 連線到指定點
+func (c *Canvas) LineTo(x, y float64) {
+	c.lineToDevice(c.state.transform.apply(x, y))
+}
+
+// QuadraticCurveTo 加入二次貝茲曲線，曲線會被切成足夠細的線段
+func (c *Canvas) QuadraticCurveTo(cpx, cpy, x, y float64) {
+	m := c.state.transform
+	p0 := c.currentPoint(m.apply(cpx, cpy))
+	cp, p1 := m.apply(cpx, cpy), m.apply(x, y)
+
+	steps := curveSteps(p0, cp, p1)
+	for i := 1; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		u := 1 - t
+		c.lineToDevice(point{
+			x: u*u*p0.x + 2*u*t*cp.x + t*t*p1.x,
+			y: u*u*p0.y + 2*u*t*cp.y + t*t*p1.y,
+		})
+	}
+}
+
+// BezierCurveTo 加入三次貝茲曲線，曲線會被切成足夠細的線段
+func (c *Canvas) BezierCurveTo(cp1x, cp1y, cp2x, cp2y, x, y float64) {
+	m := c.state.transform
+	p0 := c.currentPoint(m.apply(cp1x, cp1y))
+	cp1, cp2, p1 := m.apply(cp1x, cp1y), m.apply(cp2x, cp2y), m.apply(x, y)
+
+	steps := curveSteps(p0, cp1, cp2, p1)
+	for i := 1; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		u := 1 - t
+		c.lineToDevice(point{
+			x: u*u*u*p0.x + 3*u*u*t*cp1.x + 3*u*t*t*cp2.x + t*t*t*p1.x,
+			y: u*u*u*p0.y + 3*u*u*t*cp1.y + 3*u*t*t*cp2.y + t*t*t*p1.y,
+		})
+	}
+}
+
+// currentPoint 回傳目前子路徑的終點（裝置座標）。
+// 與 Canvas 相同，沒有子路徑時以 fallback 開始新的子路徑
+func (c *Canvas) currentPoint(fallback point) point {
+	n := len(c.path)
+	if n == 0 || len(c.path[n-1].points) == 0 {
+		c.lineToDevice(fallback)
+		return fallback
+	}
+	sp := c.path[n-1].points
+	return sp[len(sp)-1]
+}
+
+// curveSteps 依控制多邊形在裝置上的長度決定曲線要切成幾段
+func curveSteps(points ...point) int {
+	length := 0.0
+	for i := 1; i < len(points); i++ {
+		length += math.Hypot(points[i].x-points[i-1].x, points[i].y-points[i-1].y)
+	}
+	steps := int(math.Ceil(length / 2))
+	if steps < 1 {
+		steps = 1
+	}
+	if steps > 64 {
+		steps = 64
+	}
+	return steps
+}
+
+// Rect 加入一個封閉的矩形子路徑
+func (c *Canvas) Rect(x, y, width, height float64) {
+	m := c.state.transform
+	c.path = append(c.path, subpath{
+		points: []point{
+			m.apply(x, y),
+			m.apply(x+width, y),
+			m.apply(x+width, y+height),
+			m.apply(x, y+height),
+		},
+		closed: true,
+	})
+	c.path = append(c.path, subpath{points: []point{m.apply(x, y)}})
+}
+
+// Arc 加入圓弧，圓弧會被切成足夠細的線段
+func (c *Canvas) Arc(x, y, radius, startAngle, endAngle float64, counterclockwise bool) {
+	c.Ellipse(x, y, radius, radius, 0, startAngle, endAngle, counterclockwise)
+}
+
+// Ellipse 加入橢圓弧，rotation 為橢圓本身的旋轉角度
+func (c *Canvas) Ellipse(x, y, radiusX, radiusY, rotation, startAngle, endAngle float64, counterclockwise bool) {
+	sweep := endAngle - startAngle
+	if counterclockwise {
+		if sweep > 0 {
+			sweep = math.Mod(sweep, 2*math.Pi) - 2*math.Pi
+		}
+		if sweep < -2*math.Pi {
+			sweep = -2 * math.Pi
+		}
+	} else {
+		if sweep < 0 {
+			sweep = math.Mod(sweep, 2*math.Pi) + 2*math.Pi
+		}
+		if sweep > 2*math.Pi {
+			sweep = 2 * math.Pi
+		}
+	}
+
+	deviceRadius := math.Max(radiusX, radiusY) * c.state.transform.scale()
+	steps := int(math.Ceil(math.Abs(sweep) * math.Sqrt(math.Max(deviceRadius, 1)) * 2))
+	if steps < 8 {
+		steps = 8
+	}
+
+	cos, sin := math.Cos(rotation), math.Sin(rotation)
+	for i := 0; i <= steps; i++ {
+		a := startAngle + sweep*float64(i)/float64(steps)
+		ex, ey := radiusX*math.Cos(a), radiusY*math.Sin(a)
+		c.lineToDevice(c.state.transform.apply(x+ex*cos-ey*sin, y+ex*sin+ey*cos))
+	}
+}
+
+// lineToDevice 以裝置座標延伸目前的子路徑，沒有子路徑時開始新的
+func (c *Canvas) lineToDevice(p point) {
+	n := len(c.path)
+	if n == 0 {
+		c.path = append(c.path, subpath{points: []point{p}})
+		return
+	}
+	c.path[n-1].points = append(c.path[n-1].points, p)
+}
+
+// Stroke 以目前的線條樣式描繪路徑
+func (c *Canvas) Stroke() {
+	halfWidth := c.state.lineWidth * c.state.transform.scale() / 2
+	c.strokeSegments(c.pathSegments(true), halfWidth, c.state.strokeColor)
+}
+
+// Fill 以目前的填滿樣式填滿路徑（nonzero 規則）
+func (c *Canvas) Fill() {
+	c.fillSegments(c.pathSegments(false), c.state.fillColor)
+}
+
+// Clip 以目前的路徑（nonzero 規則）限制之後繪製的範圍，與原本的裁剪範圍取交集
+func (c *Canvas) Clip() {
+	m := c.fillMask(c.pathSegments(false))
+	if m == nil {
+		m = newMask(image.Rectangle{})
+	}
+	if c.state.clip != nil {
+		m = m.intersect(c.state.clip)
+	}
+	c.state.clip = m
+}
+
+// clipCoverage 回傳像素在裁剪範圍內的比例
+func (c *Canvas) clipCoverage(x, y int) float64 {
+	if c.state.clip == nil {
+		return 1
+	}
+	if !image.Pt(x, y).In(c.state.clip.area) {
+		return 0
+	}
+	return math.Min(float64(c.state.clip.at(x, y)), 1)
+}
+
+// FillText 以內建點陣字型繪製文字，y 為文字基線
+func (c *Canvas) FillText(text string, x, y float64) {
+	unit := fontSize(c.state.font) / 10
+	m := c.state.transform
+
+	var segs []segment
+	addRect := func(x0, y0, x1, y1 float64) {
+		p0, p1, p2, p3 := m.apply(x0, y0), m.apply(x1, y0), m.apply(x1, y1), m.apply(x0, y1)
+		segs = append(segs, segment{p0, p1}, segment{p1, p2}, segment{p2, p3}, segment{p3, p0})
+	}
+
+	cx := x
+	for _, r := range text {
+		glyph := glyphFor(r)
+		for row, bits := range glyph {
+			top := y - float64(7-row)*unit
+			// 將同一列相鄰的點合併成一個矩形
+			for col := 0; col < 5; {
+				if bits&(1<<(4-col)) == 0 {
+					col++
+					continue
+				}
+				start := col
+				for col < 5 && bits&(1<<(4-col)) != 0 {
+					col++
+				}
+				addRect(cx+float64(start)*unit, top, cx+float64(col)*unit, top+unit)
+			}
+		}
+		cx += 6 * unit
+	}
+
+	c.fillSegments(segs, c.state.fillColor)
+}
+
+// ClearRect 將矩形範圍清成透明，只清除裁剪範圍內的部分
+func (c *Canvas) ClearRect(x, y, width, height float64) {
+	m := c.state.transform
+	corners := []point{m.apply(x, y), m.apply(x+width, y), m.apply(x, y+height), m.apply(x+width, y+height)}
+	area := boundsOf(corners).Intersect(c.img.Bounds())
+	for py := area.Min.Y; py < area.Max.Y; py++ {
+		for px := area.Min.X; px < area.Max.X; px++ {
+			keep := 1 - c.clipCoverage(px, py)
+			if keep <= 0 {
+				c.img.SetRGBA(px, py, color.RGBA{})
+				continue
+			}
+			// 影像是預乘 alpha，部分在裁剪範圍內的像素等比例變淡
+			i := c.img.PixOffset(px, py)
+			for k := 0; k < 4; k++ {
+				c.img.Pix[i+k] = uint8(math.Round(float64(c.img.Pix[i+k]) * keep))
+			}
+		}
+	}
+}
+
+// segment 是裝置座標中的線段
+type segment struct {
+	a, b point
+}
+
+// pathSegments 將路徑轉成線段，fill 時所有子路徑都視為封閉
+func (c *Canvas) pathSegments(stroke bool) []segment {
+	var segs []segment
+	for _, sp := range c.path {
+		pts := sp.points
+		if len(pts) < 2 {
+			continue
+		}
+		for i := 1; i < len(pts); i++ {
+			segs = append(segs, segment{pts[i-1], pts[i]})
+		}
+		if sp.closed || !stroke {
+			segs = append(segs, segment{pts[len(pts)-1], pts[0]})
+		}
+	}
+	return segs
+}
+
+// strokeSegments 以反鋸齒的方式描繪線段；
+// 每個像素取所有線段中最大的覆蓋率，避免接點重複疊色
+func (c *Canvas) strokeSegments(segs []segment, halfWidth float64, col color.NRGBA) {
+	if len(segs) == 0 {
+		return
+	}
+
+	var pts []point
+	for _, s := range segs {
+		pts = append(pts, s.a, s.b)
+	}
+	area := boundsOf(pts).Inset(-int(math.Ceil(halfWidth)) - 1).Intersect(c.img.Bounds())
+	if area.Empty() {
+		return
+	}
+
+	mask := newMask(area)
+	for _, s := range segs {
+		sa := boundsOf([]point{s.a, s.b}).Inset(-int(math.Ceil(halfWidth)) - 1).Intersect(area)
+		for py := sa.Min.Y; py < sa.Max.Y; py++ {
+			for px := sa.Min.X; px < sa.Max.X; px++ {
+				d := distanceToSegment(point{float64(px) + 0.5, float64(py) + 0.5}, s.a, s.b)
+				cov := float32(clamp01(halfWidth + 0.5 - d))
+				if cov > mask.at(px, py) {
+					mask.set(px, py, cov)
+				}
+			}
+		}
+	}
+
+	c.composite(mask, col)
+}
+
+// fillSubsamples 每個像素列的取樣次數
+const fillSubsamples = 4
+
+// fillSegments 以 nonzero 規則填滿由線段組成的多邊形
+func (c *Canvas) fillSegments(segs []segment, col color.NRGBA) {
+	if m := c.fillMask(segs); m != nil {
+		c.composite(m, col)
+	}
+}
+
+// fillMask 以 nonzero 規則計算多邊形在每個像素的覆蓋率，完全在畫布外時回傳 nil。
+// 垂直方向多重取樣，水平方向計算精確的覆蓋長度
+func (c *Canvas) fillMask(segs []segment) *mask {
+	if len(segs) == 0 {
+		return nil
+	}
+
+	var pts []point
+	for _, s := range segs {
+		pts = append(pts, s.a, s.b)
+	}
+	area := boundsOf(pts).Inset(-1).Intersect(c.img.Bounds())
+	if area.Empty() {
+		return nil
+	}
+
+	type crossing struct {
+		x   float64
+		dir int
+	}
+
+	mask := newMask(area)
+	var xs []crossing
+	for py := area.Min.Y; py < area.Max.Y; py++ {
+		for sub := 0; sub < fillSubsamples; sub++ {
+			sy := float64(py) + (float64(sub)+0.5)/fillSubsamples
+
+			xs = xs[:0]
+			for _, s := range segs {
+				y0, y1 := s.a.y, s.b.y
+				if y0 == y1 {
+					continue
+				}
+				dir := 1
+				if y0 > y1 {
+					y0, y1 = y1, y0
+					dir = -1
+				}
+				if sy < y0 || sy >= y1 {
+					continue
+				}
+				t := (sy - s.a.y) / (s.b.y - s.a.y)
+				xs = append(xs, crossing{s.a.x + t*(s.b.x-s.a.x), dir})
+			}
+
+			// 依 x 排序（插入排序，交點數量通常很少）
+			for i := 1; i < len(xs); i++ {
+				for j := i; j > 0 && xs[j].x < xs[j-1].x; j-- {
+					xs[j], xs[j-1] = xs[j-1], xs[j]
+				}
+			}
+
+			winding := 0
+			for i := 0; i+1 < len(xs); i++ {
+				winding += xs[i].dir
+				if winding == 0 {
+					continue
+				}
+				x0 := math.Max(xs[i].x, float64(area.Min.X))
+				x1 := math.Min(xs[i+1].x, float64(area.Max.X))
+				for px := int(math.Floor(x0)); float64(px) < x1; px++ {
+					overlap := math.Min(x1, float64(px+1)) - math.Max(x0, float64(px))
+					if overlap > 0 {
+						mask.add(px, py, float32(overlap/fillSubsamples))
+					}
+				}
+			}
+		}
+	}
+
+	return mask
+}
+
+// composite 以 source-over 將顏色依覆蓋率疊到影像上
+func (c *Canvas) composite(m *mask, col color.NRGBA) {
+	srcAlpha := float64(col.A) / 255 * c.state.globalAlpha
+	for py := m.area.Min.Y; py < m.area.Max.Y; py++ {
+		for px := m.area.Min.X; px < m.area.Max.X; px++ {
+			cov := math.Min(float64(m.at(px, py)), 1) * c.clipCoverage(px, py)
+			a := srcAlpha * cov
+			if a <= 0 {
+				continue
+			}
+
+			i := c.img.PixOffset(px, py)
+			dst := c.img.Pix[i : i+4 : i+4]
+			dst[0] = blend(col.R, a, dst[0])
+			dst[1] = blend(col.G, a, dst[1])
+			dst[2] = blend(col.B, a, dst[2])
+			dst[3] = uint8(math.Round(255*a + float64(dst[3])*(1-a)))
+		}
+	}
+}
+
+// blend 以預乘 alpha 混合單一色版
+func blend(src uint8, alpha float64, dst uint8) uint8 {
+	return uint8(math.Round(float64(src)*alpha + float64(dst)*(1-alpha)))
+}
+
+// mask 記錄一個矩形範圍內每個像素的覆蓋率
+type mask struct {
+	area image.Rectangle
+	cov  []float32
+}
+
+func newMask(area image.Rectangle) *mask {
+	return &mask{area: area, cov: make([]float32, area.Dx()*area.Dy())}
+}
+
+func (m *mask) index(x, y int) int {
+	return (y-m.area.Min.Y)*m.area.Dx() + (x - m.area.Min.X)
+}
+
+func (m *mask) at(x, y int) float32 {
+	return m.cov[m.index(x, y)]
+}
+
+func (m *mask) set(x, y int, v float32) {
+	m.cov[m.index(x, y)] = v
+}
+
+func (m *mask) add(x, y int, v float32) {
+	if image.Pt(x, y).In(m.area) {
+		m.cov[m.index(x, y)] += v
+	}
+}
+
+// intersect 回傳兩個覆蓋率相乘的結果，範圍為兩者的交集
+func (m *mask) intersect(o *mask) *mask {
+	out := newMask(m.area.Intersect(o.area))
+	for y := out.area.Min.Y; y < out.area.Max.Y; y++ {
+		for x := out.area.Min.X; x < out.area.Max.X; x++ {
+			out.set(x, y, min(m.at(x, y), 1)*min(o.at(x, y), 1))
+		}
+	}
+	return out
+}
+
+// boundsOf 回傳包住所有點的整數矩形
+func boundsOf(pts []point) image.Rectangle {
+	if len(pts) == 0 {
+		return image.Rectangle{}
+	}
+	minX, minY, maxX, maxY := pts[0].x, pts[0].y, pts[0].x, pts[0].y
+	for _, p := range pts[1:] {
+		minX, maxX = math.Min(minX, p.x), math.Max(maxX, p.x)
+		minY, maxY = math.Min(minY, p.y), math.Max(maxY, p.y)
+	}
+	return image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY)))
+}
+
+// distanceToSegment 計算點到線段的距離
+func distanceToSegment(p, a, b point) float64 {
+	dx, dy := b.x-a.x, b.y-a.y
+	l2 := dx*dx + dy*dy
+	if l2 == 0 {
+		return math.Hypot(p.x-a.x, p.y-a.y)
+	}
+	t := clamp01(((p.x-a.x)*dx + (p.y-a.y)*dy) / l2)
+	return math.Hypot(p.x-(a.x+t*dx), p.y-(a.y+t*dy))
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+// fontPattern 從 CSS font 字串中取出像素大小，例如 "20px Arial"
+var fontPattern = regexp.MustCompile(`(\d+(?:\.\d+)?)px`)
+
+// fontSize 回傳字型的像素大小，無法解析時使用 Canvas 預設的 10px
+func fontSize(font string) float64 {
+	m := fontPattern.FindStringSubmatch(font)
+	if m == nil {
+		return 10
+	}
+	size, err := strconv.ParseFloat(m[1], 64)
+	if err != nil || size <= 0 {
+		return 10
+	}
+	return size
+}
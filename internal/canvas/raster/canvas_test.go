@@ -0,0 +1,111 @@
+package raster
+
+import (
+	"bytes"
+	"flag"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "重新產生 testdata 中的參考影像")
+
+// checkGolden 比較影像與 testdata 中的參考 PNG，-update 時改寫參考影像
+func checkGolden(t *testing.T, name string, img *image.RGBA) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".png")
+	if *update {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	defer f.Close()
+	want, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !want.Bounds().Eq(img.Bounds()) {
+		t.Fatalf("%s: size %v, want %v", name, img.Bounds(), want.Bounds())
+	}
+	// PNG 儲存的是未預乘 alpha 的顏色，以相同的格式比較
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			w := color.NRGBAModel.Convert(want.At(x, y))
+			g := color.NRGBAModel.Convert(img.At(x, y))
+			if w != g {
+				t.Fatalf("%s: pixel (%d, %d) = %v, want %v", name, x, y, g, w)
+			}
+		}
+	}
+}
+
+func TestGoldenStroke(t *testing.T) {
+	c := New(48, 32)
+	c.SetStrokeStyle("#1e50c8")
+	c.SetLineWidth(3)
+	c.BeginPath()
+	c.MoveTo(4, 28)
+	c.LineTo(20, 4)
+	c.QuadraticCurveTo(32, 28, 44, 8)
+	c.Stroke()
+	checkGolden(t, "stroke", c.Image())
+}
+
+func TestGoldenFill(t *testing.T) {
+	c := New(48, 32)
+	c.SetFillStyle("#d03030")
+	c.BeginPath()
+	c.Rect(4, 4, 20, 12)
+	c.Fill()
+	// 半透明的橢圓疊在矩形上
+	c.SetFillStyle("rgba(40, 160, 60, 0.5)")
+	c.BeginPath()
+	c.Ellipse(28, 18, 16, 10, 0, 0, 6.283185307179586, false)
+	c.Fill()
+	checkGolden(t, "fill", c.Image())
+}
+
+func TestGoldenText(t *testing.T) {
+	c := New(64, 24)
+	c.SetFillStyle("#000000")
+	c.SetFont("14px sans-serif")
+	c.FillText("Go 1", 4, 18)
+	checkGolden(t, "text", c.Image())
+}
+
+func TestClipLimitsDrawing(t *testing.T) {
+	c := New(20, 20)
+	c.SetFillStyle("#ff0000")
+	c.BeginPath()
+	c.Rect(0, 0, 20, 20)
+	c.Fill()
+
+	c.Save()
+	c.BeginPath()
+	c.Rect(5, 5, 10, 10)
+	c.Clip()
+	c.ClearRect(0, 0, 20, 20)
+	c.Restore()
+
+	img := c.Image()
+	if got := img.RGBAAt(2, 2); got.A != 255 {
+		t.Errorf("pixel outside the clip was cleared: %v", got)
+	}
+	if got := img.RGBAAt(10, 10); got.A != 0 {
+		t.Errorf("pixel inside the clip was not cleared: %v", got)
+	}
+}
@@ -0,0 +1,119 @@
+package raster
+
+import (
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// namedColors 支援的 CSS 顏色名稱
+var namedColors = map[string]color.NRGBA{
+	"black":       {0, 0, 0, 255},
+	"white":       {255, 255, 255, 255},
+	"red":         {255, 0, 0, 255},
+	"green":       {0, 128, 0, 255},
+	"blue":        {0, 0, 255, 255},
+	"yellow":      {255, 255, 0, 255},
+	"orange":      {255, 165, 0, 255},
+	"purple":      {128, 0, 128, 255},
+	"gray":        {128, 128, 128, 255},
+	"grey":        {128, 128, 128, 255},
+	"transparent": {0, 0, 0, 0},
+}
+
+// parseColor 解析 CSS 顏色字串：#rgb、#rgba、#rrggbb、#rrggbbaa、
+// rgb()、rgba() 以及常見的顏色名稱
+func parseColor(s string) (color.NRGBA, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	if c, ok := namedColors[s]; ok {
+		return c, true
+	}
+
+	if strings.HasPrefix(s, "#") {
+		return parseHex(s[1:])
+	}
+
+	if strings.HasPrefix(s, "rgb(") || strings.HasPrefix(s, "rgba(") {
+		if !strings.HasSuffix(s, ")") {
+			return color.NRGBA{}, false
+		}
+		args := s[strings.Index(s, "(")+1 : len(s)-1]
+		return parseRGBFunc(args)
+	}
+
+	return color.NRGBA{}, false
+}
+
+// parseHex 解析十六進位顏色
+func parseHex(hex string) (color.NRGBA, bool) {
+	var digits []uint8
+	for _, r := range hex {
+		v, err := strconv.ParseUint(string(r), 16, 8)
+		if err != nil {
+			return color.NRGBA{}, false
+		}
+		digits = append(digits, uint8(v))
+	}
+
+	switch len(digits) {
+	case 3, 4:
+		c := color.NRGBA{digits[0] * 17, digits[1] * 17, digits[2] * 17, 255}
+		if len(digits) == 4 {
+			c.A = digits[3] * 17
+		}
+		return c, true
+	case 6, 8:
+		c := color.NRGBA{
+			digits[0]<<4 | digits[1],
+			digits[2]<<4 | digits[3],
+			digits[4]<<4 | digits[5],
+			255,
+		}
+		if len(digits) == 8 {
+			c.A = digits[6]<<4 | digits[7]
+		}
+		return c, true
+	default:
+		return color.NRGBA{}, false
+	}
+}
+
+// parseRGBFunc 解析 rgb()/rgba() 的參數
+func parseRGBFunc(args string) (color.NRGBA, bool) {
+	parts := strings.Split(args, ",")
+	if len(parts) != 3 && len(parts) != 4 {
+		return color.NRGBA{}, false
+	}
+
+	var channels [3]uint8
+	for i := 0; i < 3; i++ {
+		v, err := strconv.ParseFloat(strings.TrimSpace(parts[i]), 64)
+		if err != nil {
+			return color.NRGBA{}, false
+		}
+		channels[i] = clampByte(v)
+	}
+
+	alpha := uint8(255)
+	if len(parts) == 4 {
+		a, err := strconv.ParseFloat(strings.TrimSpace(parts[3]), 64)
+		if err != nil {
+			return color.NRGBA{}, false
+		}
+		alpha = clampByte(a * 255)
+	}
+
+	return color.NRGBA{channels[0], channels[1], channels[2], alpha}, true
+}
+
+// clampByte 將數值限制在 0 到 255 並四捨五入
+func clampByte(v float64) uint8 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 255 {
+		return 255
+	}
+	return uint8(v + 0.5)
+}
@@ -0,0 +1,63 @@
+package raster
+
+import (
+	"image"
+	"image/png"
+	"io"
+	"math"
+
+	"canvas-demo/internal/canvas/layer"
+	"canvas-demo/internal/canvas/shape"
+)
+
+// maxImageSize 輸出影像每邊的最大像素數，範圍更大時等比例縮小
+const maxImageSize = 8192
+
+// Render 將形狀繪製到新的透明影像上，view 指定要輸出的範圍
+func Render(shapes []shape.Shape, view shape.Bounds) *image.RGBA {
+	c := newView(view)
+	for _, s := range shapes {
+		s.Draw(c)
+	}
+	return c.Image()
+}
+
+// RenderLayers 依圖層的顯示與透明度設定繪製到新的透明影像上
+func RenderLayers(layers []*layer.Layer, view shape.Bounds) *image.RGBA {
+	c := newView(view)
+	for _, l := range layers {
+		l.Draw(c)
+	}
+	return c.Image()
+}
+
+// newView 創建涵蓋 view 範圍的畫布，範圍的長邊超過 maxImageSize 時等比例縮小
+func newView(view shape.Bounds) *Canvas {
+	scale := 1.0
+	if size := math.Max(view.Width, view.Height); size > maxImageSize {
+		scale = maxImageSize / size
+	}
+
+	c := New(pixels(view.Width*scale), pixels(view.Height*scale))
+	c.Scale(scale, scale)
+	c.Translate(-view.X, -view.Y)
+	return c
+}
+
+// pixels 將長度換算成 1 到 maxImageSize 之間的像素數，不是有限數值時為 1
+func pixels(length float64) int {
+	if !(length >= 1) {
+		return 1
+	}
+	return int(math.Min(math.Ceil(length), maxImageSize))
+}
+
+// EncodePNG 將形狀繪製後以 PNG 格式寫出
+func EncodePNG(w io.Writer, shapes []shape.Shape, view shape.Bounds) error {
+	return png.Encode(w, Render(shapes, view))
+}
+
+// EncodePNGLayers 將圖層繪製後以 PNG 格式寫出
+func EncodePNGLayers(w io.Writer, layers []*layer.Layer, view shape.Bounds) error {
+	return png.Encode(w, RenderLayers(layers, view))
+}
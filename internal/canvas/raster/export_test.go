@@ -0,0 +1,41 @@
+package raster
+
+import (
+	"math"
+	"testing"
+
+	"canvas-demo/internal/canvas/shape"
+)
+
+func TestRenderLimitsImageSize(t *testing.T) {
+	near := shape.NewRect(shape.Style{FillStyle: "#000000"})
+	near.SetCorners(shape.Point{X: 0, Y: 0}, shape.Point{X: 1000, Y: 1000})
+	far := shape.NewRect(shape.Style{FillStyle: "#000000"})
+	far.SetCorners(shape.Point{X: 1e6, Y: 0}, shape.Point{X: 1e6 + 1000, Y: 1000})
+	view := near.GetBounds().Union(far.GetBounds())
+
+	img := Render([]shape.Shape{near, far}, view)
+	b := img.Bounds()
+	if b.Dx() > maxImageSize || b.Dy() > maxImageSize {
+		t.Fatalf("image size %v exceeds %d", b.Size(), maxImageSize)
+	}
+	// 縮小後兩個形狀仍然在影像中
+	if img.RGBAAt(0, 0).A == 0 {
+		t.Error("near shape is missing from the downscaled image")
+	}
+	if img.RGBAAt(b.Dx()-1, 0).A == 0 {
+		t.Error("far shape is missing from the downscaled image")
+	}
+}
+
+func TestRenderDegenerateView(t *testing.T) {
+	for _, view := range []shape.Bounds{
+		{},
+		{Width: math.NaN(), Height: 10},
+		{Width: math.Inf(1), Height: math.Inf(1)},
+	} {
+		if b := Render(nil, view).Bounds(); b.Dx() < 1 || b.Dy() < 1 || b.Dx() > maxImageSize || b.Dy() > maxImageSize {
+			t.Errorf("Render(%v) size %v", view, b.Size())
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package raster
+
+import "math"
+
+// matrix 是 2D 仿射轉換，與 Canvas 的 setTransform(a, b, c, d, e, f) 相同：
+//
+//	x' = a*x + c*y + e
+//	y' = b*x + d*y + f
+type matrix struct {
+	a, b, c, d, e, f float64
+}
+
+func identity() matrix {
+	return matrix{a: 1, d: 1}
+}
+
+func translation(x, y float64) matrix {
+	return matrix{a: 1, d: 1, e: x, f: y}
+}
+
+func scaling(x, y float64) matrix {
+	return matrix{a: x, d: y}
+}
+
+func rotation(angle float64) matrix {
+	cos, sin := math.Cos(angle), math.Sin(angle)
+	return matrix{a: cos, b: sin, c: -sin, d: cos}
+}
+
+// multiply 回傳 m × n，也就是先套用 n 再套用 m
+func (m matrix) multiply(n matrix) matrix {
+	return matrix{
+		a: m.a*n.a + m.c*n.b,
+		b: m.b*n.a + m.d*n.b,
+		c: m.a*n.c + m.c*n.d,
+		d: m.b*n.c + m.d*n.d,
+		e: m.a*n.e + m.c*n.f + m.e,
+		f: m.b*n.e + m.d*n.f + m.f,
+	}
+}
+
+// apply 將點轉換到裝置座標
+func (m matrix) apply(x, y float64) point {
+	return point{m.a*x + m.c*y + m.e, m.b*x + m.d*y + m.f}
+}
+
+// scale 回傳轉換的平均縮放倍率，用於換算線寬
+func (m matrix) scale() float64 {
+	return math.Sqrt(math.Abs(m.a*m.d - m.b*m.c))
+}
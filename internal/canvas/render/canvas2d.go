@@ -0,0 +1,63 @@
+//go:build js && wasm
+
+package render
+
+import (
+	"syscall/js"
+)
+
+// Canvas2D 以瀏覽器的 CanvasRenderingContext2D 實作 Renderer
+type Canvas2D struct {
+	ctx js.Value
+}
+
+// NewCanvas2D 包裝一個 2D context
+func NewCanvas2D(ctx js.Value) *Canvas2D {
+	return &Canvas2D{ctx: ctx}
+}
+
+func (c *Canvas2D) Save()                        { c.ctx.Call("save") }
+func (c *Canvas2D) Restore()                     { c.ctx.Call("restore") }
+func (c *Canvas2D) SetStrokeStyle(style string)  { c.ctx.Set("strokeStyle", style) }
+func (c *Canvas2D) SetFillStyle(style string)    { c.ctx.Set("fillStyle", style) }
+func (c *Canvas2D) SetLineWidth(width float64)   { c.ctx.Set("lineWidth", width) }
+func (c *Canvas2D) SetGlobalAlpha(alpha float64) { c.ctx.Set("globalAlpha", alpha) }
+func (c *Canvas2D) SetFont(font string)          { c.ctx.Set("font", font) }
+func (c *Canvas2D) Translate(x, y float64)       { c.ctx.Call("translate", x, y) }
+func (c *Canvas2D) Rotate(angle float64)         { c.ctx.Call("rotate", angle) }
+func (c *Canvas2D) Scale(x, y float64)           { c.ctx.Call("scale", x, y) }
+func (c *Canvas2D) BeginPath()                   { c.ctx.Call("beginPath") }
+func (c *Canvas2D) ClosePath()                   { c.ctx.Call("closePath") }
+func (c *Canvas2D) MoveTo(x, y float64)          { c.ctx.Call("moveTo", x, y) }
+func (c *Canvas2D) LineTo(x, y float64)          { c.ctx.Call("lineTo", x, y) }
+func (c *Canvas2D) Stroke()                      { c.ctx.Call("stroke") }
+func (c *Canvas2D) Fill()                        { c.ctx.Call("fill") }
+func (c *Canvas2D) Clip()                        { c.ctx.Call("clip") }
+
+func (c *Canvas2D) Rect(x, y, width, height float64) {
+	c.ctx.Call("rect", x, y, width, height)
+}
+
+func (c *Canvas2D) QuadraticCurveTo(cpx, cpy, x, y float64) {
+	c.ctx.Call("quadraticCurveTo", cpx, cpy, x, y)
+}
+
+func (c *Canvas2D) BezierCurveTo(cp1x, cp1y, cp2x, cp2y, x, y float64) {
+	c.ctx.Call("bezierCurveTo", cp1x, cp1y, cp2x, cp2y, x, y)
+}
+
+func (c *Canvas2D) Arc(x, y, radius, startAngle, endAngle float64, counterclockwise bool) {
+	c.ctx.Call("arc", x, y, radius, startAngle, endAngle, counterclockwise)
+}
+
+func (c *Canvas2D) Ellipse(x, y, radiusX, radiusY, rotation, startAngle, endAngle float64, counterclockwise bool) {
+	c.ctx.Call("ellipse", x, y, radiusX, radiusY, rotation, startAngle, endAngle, counterclockwise)
+}
+
+func (c *Canvas2D) FillText(text string, x, y float64) {
+	c.ctx.Call("fillText", text, x, y)
+}
+
+func (c *Canvas2D) ClearRect(x, y, width, height float64) {
+	c.ctx.Call("clearRect", x, y, width, height)
+}
@@ -0,0 +1,82 @@
+package render
+
+// Call 記錄一次 Renderer 方法呼叫
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Recorder 將所有繪圖呼叫記錄下來而不實際繪製，
+// 用於非瀏覽器環境（例如原生建置與測試）
+type Recorder struct {
+	Calls []Call
+}
+
+// NewRecorder 創建新的 Recorder
+func NewRecorder() *Recorder {
+	return &Recorder{Calls: make([]Call, 0)}
+}
+
+// Reset 清除已記錄的呼叫
+func (r *Recorder) Reset() {
+	r.Calls = r.Calls[:0]
+}
+
+// Methods 依序回傳已記錄的方法名稱
+func (r *Recorder) Methods() []string {
+	methods := make([]string, len(r.Calls))
+	for i, c := range r.Calls {
+		methods[i] = c.Method
+	}
+	return methods
+}
+
+func (r *Recorder) record(method string, args ...interface{}) {
+	r.Calls = append(r.Calls, Call{Method: method, Args: args})
+}
+
+func (r *Recorder) Save()                        { r.record("save") }
+func (r *Recorder) Restore()                     { r.record("restore") }
+func (r *Recorder) SetStrokeStyle(style string)  { r.record("strokeStyle", style) }
+func (r *Recorder) SetFillStyle(style string)    { r.record("fillStyle", style) }
+func (r *Recorder) SetLineWidth(width float64)   { r.record("lineWidth", width) }
+func (r *Recorder) SetGlobalAlpha(alpha float64) { r.record("globalAlpha", alpha) }
+func (r *Recorder) SetFont(font string)          { r.record("font", font) }
+func (r *Recorder) Translate(x, y float64)       { r.record("translate", x, y) }
+func (r *Recorder) Rotate(angle float64)         { r.record("rotate", angle) }
+func (r *Recorder) Scale(x, y float64)           { r.record("scale", x, y) }
+func (r *Recorder) BeginPath()                   { r.record("beginPath") }
+func (r *Recorder) ClosePath()                   { r.record("closePath") }
+func (r *Recorder) MoveTo(x, y float64)          { r.record("moveTo", x, y) }
+func (r *Recorder) LineTo(x, y float64)          { r.record("lineTo", x, y) }
+func (r *Recorder) Stroke()                      { r.record("stroke") }
+func (r *Recorder) Fill()                        { r.record("fill") }
+func (r *Recorder) Clip()                        { r.record("clip") }
+
+func (r *Recorder) Rect(x, y, width, height float64) {
+	r.record("rect", x, y, width, height)
+}
+
+func (r *Recorder) QuadraticCurveTo(cpx, cpy, x, y float64) {
+	r.record("quadraticCurveTo", cpx, cpy, x, y)
+}
+
+func (r *Recorder) BezierCurveTo(cp1x, cp1y, cp2x, cp2y, x, y float64) {
+	r.record("bezierCurveTo", cp1x, cp1y, cp2x, cp2y, x, y)
+}
+
+func (r *Recorder) Arc(x, y, radius, startAngle, endAngle float64, counterclockwise bool) {
+	r.record("arc", x, y, radius, startAngle, endAngle, counterclockwise)
+}
+
+func (r *Recorder) Ellipse(x, y, radiusX, radiusY, rotation, startAngle, endAngle float64, counterclockwise bool) {
+	r.record("ellipse", x, y, radiusX, radiusY, rotation, startAngle, endAngle, counterclockwise)
+}
+
+func (r *Recorder) FillText(text string, x, y float64) {
+	r.record("fillText", text, x, y)
+}
+
+func (r *Recorder) ClearRect(x, y, width, height float64) {
+	r.record("clearRect", x, y, width, height)
+}
@@ -0,0 +1,36 @@
+// Package render 定義與繪圖後端無關的 Renderer 介面，
+// 讓形狀模型不需要直接依賴 syscall/js。
+package render
+
+// Renderer 抽象 Canvas 2D 的繪圖操作
+type Renderer interface {
+	// 狀態
+	Save()
+	Restore()
+	SetStrokeStyle(style string)
+	SetFillStyle(style string)
+	SetLineWidth(width float64)
+	SetGlobalAlpha(alpha float64)
+	SetFont(font string)
+	Translate(x, y float64)
+	Rotate(angle float64)
+	Scale(x, y float64)
+
+	// 路徑
+	BeginPath()
+	ClosePath()
+	MoveTo(x, y float64)
+	LineTo(x, y float64)
+	QuadraticCurveTo(cpx, cpy, x, y float64)
+	BezierCurveTo(cp1x, cp1y, cp2x, cp2y, x, y float64)
+	Rect(x, y, width, height float64)
+	Arc(x, y, radius, startAngle, endAngle float64, counterclockwise bool)
+	Ellipse(x, y, radiusX, radiusY, rotation, startAngle, endAngle float64, counterclockwise bool)
+
+	// 繪製
+	Stroke()
+	Fill()
+	Clip()
+	FillText(text string, x, y float64)
+	ClearRect(x, y, width, height float64)
+}
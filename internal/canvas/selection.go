@@ -0,0 +1,174 @@
+//go:build js && wasm
+
+package canvas
+
+import (
+	"math"
+
+	"canvas-demo/internal/canvas/shape"
+)
+
+// setSelectedShape 設置唯一選中的形狀，s 為 nil 時取消所有選取
+func (cm *CanvasManager) setSelectedShape(s shape.Shape) {
+	if s == nil {
+		cm.setSelection(nil)
+		return
+	}
+	cm.setSelection([]shape.Shape{s})
+}
+
+// setSelection 以 shapes 取代目前的選取
+func (cm *CanvasManager) setSelection(shapes []shape.Shape) {
+	cm.selection = append([]shape.Shape(nil), shapes...)
+	cm.repaint()
+}
+
+// toggleSelected 將形狀加入選取，已在選取中時移除
+func (cm *CanvasManager) toggleSelected(s shape.Shape) {
+	shapes := make([]shape.Shape, 0, len(cm.selection)+1)
+	for _, sel := range cm.selection {
+		if sel != s {
+			shapes = append(shapes, sel)
+		}
+	}
+	if len(shapes) == len(cm.selection) {
+		shapes = append(shapes, s)
+	}
+	cm.setSelection(shapes)
+}
+
+// isSelected 檢查形狀是否在選取中
+func (cm *CanvasManager) isSelected(s shape.Shape) bool {
+	return containsShape(cm.selection, s)
+}
+
+// singleSelection 只選取一個形狀時回傳該形狀，否則回傳 nil
+func (cm *CanvasManager) singleSelection() shape.Shape {
+	if len(cm.selection) == 1 {
+		return cm.selection[0]
+	}
+	return nil
+}
+
+// selectedShapes 回傳選取的副本，供命令保存
+func (cm *CanvasManager) selectedShapes() []shape.Shape {
+	return append([]shape.Shape(nil), cm.selection...)
+}
+
+// syncSelection 取消已不在畫布上，或所在圖層被隱藏、鎖定的形狀的選取
+func (cm *CanvasManager) syncSelection() {
+	shapes := make([]shape.Shape, 0, len(cm.selection))
+	for _, s := range cm.selection {
+		if cm.canSelect(s) {
+			shapes = append(shapes, s)
+		}
+	}
+	if len(shapes) != len(cm.selection) {
+		cm.setSelection(shapes)
+	}
+}
+
+// selectInMarquee 依框選範圍更新選取：由左往右拖曳時選取完全在範圍內的形狀，
+// 由右往左拖曳時選取與範圍相交的形狀。按住 Shift 開始框選時保留原本的選取
+func (cm *CanvasManager) selectInMarquee() {
+	area := cm.marquee()
+	crossing := cm.marqueeEnd.X < cm.anchor.X
+
+	shapes := append([]shape.Shape(nil), cm.marqueeBase...)
+	candidates := cm.shapesIn(area)
+	for _, s := range cm.editableShapes() {
+		if !candidates[s] || containsShape(shapes, s) {
+			continue
+		}
+		if crossing && shape.IntersectsRect(s, area) || !crossing && area.Encloses(s.GetBounds()) {
+			shapes = append(shapes, s)
+		}
+	}
+	cm.setSelection(shapes)
+}
+
+// marquee 回傳目前的框選範圍
+func (cm *CanvasManager) marquee() shape.Bounds {
+	a, b := cm.anchor, cm.marqueeEnd
+	return shape.Bounds{
+		X:      math.Min(a.X, b.X),
+		Y:      math.Min(a.Y, b.Y),
+		Width:  math.Abs(b.X - a.X),
+		Height: math.Abs(b.Y - a.Y),
+	}
+}
+
+// selectionBounds 回傳多選時合併的邊界框
+func (cm *CanvasManager) selectionBounds() shape.Bounds {
+	return shape.UnionBounds(cm.selection)
+}
+
+// hitSelectionControl 檢查是否點擊到選取的控制點。
+// 只選取一個形狀時使用形狀自己的控制點，多選時使用合併邊界框的控制點
+func (cm *CanvasManager) hitSelectionControl(p shape.Point) shape.ControlPoint {
+	if s := cm.singleSelection(); s != nil {
+		return s.HitControl(p, cm.view.scale)
+	}
+	return shape.HitBoundsControls(p, cm.selectionBounds(), 0, cm.view.scale)
+}
+
+// selectionControlPosition 回傳選取控制點的位置，shape.None 為中心
+func (cm *CanvasManager) selectionControlPosition(cp shape.ControlPoint) shape.Point {
+	if s := cm.singleSelection(); s != nil {
+		return shape.ControlPosition(s, cp, cm.view.scale)
+	}
+	return shape.BoundsControlPosition(cm.selectionBounds(), 0, cp, cm.view.scale)
+}
+
+// selectionRotation 回傳選取邊界框的旋轉角度，多選時的合併邊界框不旋轉
+func (cm *CanvasManager) selectionRotation() float64 {
+	if s := cm.singleSelection(); s != nil {
+		return shape.Rotation(s)
+	}
+	return 0
+}
+
+// getScaleCenter 根據控制點獲取縮放中心：對角的控制點（已套用旋轉）
+func (cm *CanvasManager) getScaleCenter(cp shape.ControlPoint) shape.Point {
+	return cm.selectionControlPosition(cp.Opposite())
+}
+
+// drawSelection 繪製選取的控制點與框選範圍
+func (cm *CanvasManager) drawSelection() {
+	switch {
+	case len(cm.selection) == 1:
+		cm.selection[0].DrawControls(cm.renderer, cm.view.scale)
+	case len(cm.selection) > 1:
+		// 各形狀以細框標示，外面再畫合併的邊界框與控制點
+		cm.renderer.SetStrokeStyle("#4a90d9")
+		cm.renderer.SetLineWidth(cm.view.screenLength(1))
+		for _, s := range cm.selection {
+			b := s.GetBounds()
+			cm.renderer.BeginPath()
+			cm.renderer.Rect(b.X, b.Y, b.Width, b.Height)
+			cm.renderer.Stroke()
+		}
+		shape.DrawBoundsControls(cm.renderer, cm.selectionBounds(), 0, cm.view.scale)
+	}
+
+	if cm.isSelecting {
+		area := cm.marquee()
+		cm.renderer.SetFillStyle("rgba(74, 144, 217, 0.1)")
+		cm.renderer.SetStrokeStyle("#4a90d9")
+		cm.renderer.SetLineWidth(cm.view.screenLength(1))
+		cm.renderer.BeginPath()
+		cm.renderer.Rect(area.X, area.Y, area.Width, area.Height)
+		cm.renderer.Fill()
+		cm.renderer.Stroke()
+	}
+}
+
+// containsShape 檢查 shapes 中是否有 s
+func containsShape(shapes []shape.Shape, s shape.Shape) bool {
+	for _, v := range shapes {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,275 @@
+package shape
+
+import (
+	"fmt"
+	"math"
+
+	"canvas-demo/internal/canvas/render"
+)
+
+// Marker 表示直線端點的標記
+type Marker string
+
+const (
+	MarkerNone  Marker = "none"
+	MarkerArrow Marker = "arrow"
+	MarkerDot   Marker = "dot"
+	MarkerBar   Marker = "bar"
+)
+
+// ParseMarker 解析標記名稱，空字串視為 MarkerNone
+func ParseMarker(s string) (Marker, error) {
+	switch m := Marker(s); m {
+	case "":
+		return MarkerNone, nil
+	case MarkerNone, MarkerArrow, MarkerDot, MarkerBar:
+		return m, nil
+	default:
+		return MarkerNone, fmt.Errorf("unknown marker %q", s)
+	}
+}
+
+// MarkerGeometry 描述一個端點標記的幾何形狀
+type MarkerGeometry struct {
+	Kind   Marker
+	Points []Point // arrow：三角形的三個頂點；bar：橫線的兩端；dot：圓心
+	Radius float64 // dot 的半徑
+}
+
+// Connector 表示兩點之間的直線，可在兩端加上箭頭等標記
+type Connector struct {
+	Start       Point
+	End         Point
+	Style       Style
+	StartMarker Marker
+	EndMarker   Marker
+}
+
+// NewConnector 創建新的直線
+func NewConnector(style Style, startMarker, endMarker Marker) *Connector {
+	return &Connector{
+		Style:       style,
+		StartMarker: startMarker,
+		EndMarker:   endMarker,
+	}
+}
+
+// Clone 回傳直線的複製
+func (c *Connector) Clone() Shape {
+	clone := *c
+	return &clone
+}
+
+// SetEndpoints 設置兩個端點
+func (c *Connector) SetEndpoints(start, end Point) {
+	c.Start = start
+	c.End = end
+}
+
+// SetEndpoint 依控制點設置其中一個端點
+func (c *Connector) SetEndpoint(cp ControlPoint, p Point) {
+	switch cp {
+	case StartPoint:
+		c.Start = p
+	case EndPoint:
+		c.End = p
+	}
+}
+
+// Endpoint 依控制點回傳其中一個端點
+func (c *Connector) Endpoint(cp ControlPoint) Point {
+	if cp == StartPoint {
+		return c.Start
+	}
+	return c.End
+}
+
+// Draw 繪製直線與端點標記
+func (c *Connector) Draw(r render.Renderer) {
+	r.SetStrokeStyle(c.Style.StrokeStyle)
+	r.SetFillStyle(c.Style.StrokeStyle)
+	r.SetLineWidth(c.Style.LineWidth)
+
+	start, end := c.Shaft()
+	r.BeginPath()
+	r.MoveTo(start.X, start.Y)
+	r.LineTo(end.X, end.Y)
+	r.Stroke()
+
+	for _, m := range c.MarkerGeometry() {
+		r.BeginPath()
+		switch m.Kind {
+		case MarkerArrow:
+			r.MoveTo(m.Points[0].X, m.Points[0].Y)
+			r.LineTo(m.Points[1].X, m.Points[1].Y)
+			r.LineTo(m.Points[2].X, m.Points[2].Y)
+			r.ClosePath()
+			r.Fill()
+		case MarkerDot:
+			r.Arc(m.Points[0].X, m.Points[0].Y, m.Radius, 0, 2*math.Pi, false)
+			r.Fill()
+		case MarkerBar:
+			r.MoveTo(m.Points[0].X, m.Points[0].Y)
+			r.LineTo(m.Points[1].X, m.Points[1].Y)
+			r.Stroke()
+		}
+	}
+}
+
+// MarkerGeometry 回傳兩端標記的幾何形狀
+func (c *Connector) MarkerGeometry() []MarkerGeometry {
+	var markers []MarkerGeometry
+	if m, ok := markerAt(c.StartMarker, c.Start, c.End, c.markerSize()); ok {
+		markers = append(markers, m)
+	}
+	if m, ok := markerAt(c.EndMarker, c.End, c.Start, c.markerSize()); ok {
+		markers = append(markers, m)
+	}
+	return markers
+}
+
+// Shaft 回傳扣除兩端標記後要描繪的線段，線寬較大時線段才不會從箭頭或圓點中透出。
+// 兩端的標記重疊時線段的長度為 0
+func (c *Connector) Shaft() (start, end Point) {
+	length := math.Hypot(c.End.X-c.Start.X, c.End.Y-c.Start.Y)
+	if length == 0 {
+		return c.Start, c.End
+	}
+	var from, to float64
+	if m, ok := markerAt(c.StartMarker, c.Start, c.End, c.markerSize()); ok {
+		from = m.inset()
+	}
+	if m, ok := markerAt(c.EndMarker, c.End, c.Start, c.markerSize()); ok {
+		to = m.inset()
+	}
+	t0 := math.Min(from/length, 1)
+	t1 := math.Max(1-to/length, t0)
+	return lerp(c.Start, c.End, t0), lerp(c.Start, c.End, t1)
+}
+
+// inset 回傳線段在標記一端要縮短的長度：箭頭為尖端到底邊的距離，圓點為半徑。
+// 橫線與線段垂直，不需要縮短
+func (m MarkerGeometry) inset() float64 {
+	switch m.Kind {
+	case MarkerArrow:
+		base := lerp(m.Points[1], m.Points[2], 0.5)
+		return math.Hypot(m.Points[0].X-base.X, m.Points[0].Y-base.Y)
+	case MarkerDot:
+		return m.Radius
+	}
+	return 0
+}
+
+// markerSize 標記大小隨線寬變化
+func (c *Connector) markerSize() float64 {
+	return math.Max(10, c.Style.LineWidth*4)
+}
+
+// markerAt 計算位於 tip、朝向遠離 tail 方向的標記
+func markerAt(kind Marker, tip, tail Point, size float64) (MarkerGeometry, bool) {
+	dx, dy := tip.X-tail.X, tip.Y-tail.Y
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		// 沒有方向時只能畫圓點
+		if kind != MarkerDot {
+			return MarkerGeometry{}, false
+		}
+		dx, length = 1, 1
+	}
+
+	// 沿線方向與法線方向的單位向量
+	ux, uy := dx/length, dy/length
+	nx, ny := -uy, ux
+
+	switch kind {
+	case MarkerArrow:
+		baseX, baseY := tip.X-ux*size, tip.Y-uy*size
+		half := size / 2
+		return MarkerGeometry{Kind: kind, Points: []Point{
+			tip,
+			{X: baseX + nx*half, Y: baseY + ny*half},
+			{X: baseX - nx*half, Y: baseY - ny*half},
+		}}, true
+	case MarkerDot:
+		return MarkerGeometry{Kind: kind, Points: []Point{tip}, Radius: size / 3}, true
+	case MarkerBar:
+		half := size / 2
+		return MarkerGeometry{Kind: kind, Points: []Point{
+			{X: tip.X + nx*half, Y: tip.Y + ny*half},
+			{X: tip.X - nx*half, Y: tip.Y - ny*half},
+		}}, true
+	default:
+		return MarkerGeometry{}, false
+	}
+}
+
+// DrawControls 在兩個端點上繪製控制點
+func (c *Connector) DrawControls(r render.Renderer, scale float64) {
+	r.Save()
+	r.SetFillStyle("#ffffff")
+	r.SetStrokeStyle("#000000")
+	r.SetLineWidth(screenLength(1, scale))
+
+	for _, p := range []Point{c.Start, c.End} {
+		r.BeginPath()
+		r.Arc(p.X, p.Y, screenLength(controlSize, scale), 0, 2*math.Pi, false)
+		r.Fill()
+		r.Stroke()
+	}
+
+	r.Restore()
+}
+
+// HitControl 檢查是否點擊到端點的控制點
+func (c *Connector) HitControl(p Point, scale float64) ControlPoint {
+	// 先檢查終點，讓重疊時優先拖曳最後畫的一端
+	if distance(p, c.End) <= screenTolerance(controlHitArea, scale) {
+		return EndPoint
+	}
+	if distance(p, c.Start) <= screenTolerance(controlHitArea, scale) {
+		return StartPoint
+	}
+	return None
+}
+
+// Contains 檢查點是否在直線上
+func (c *Connector) Contains(p Point, scale float64) bool {
+	return pointToLineDistance(p, c.Start, c.End) <= screenTolerance(hitTolerance, scale)
+}
+
+// Move 移動直線
+func (c *Connector) Move(dx, dy float64) {
+	c.Start.X += dx
+	c.Start.Y += dy
+	c.End.X += dx
+	c.End.Y += dy
+}
+
+// GetBounds 獲取直線的邊界，包含端點標記
+func (c *Connector) GetBounds() Bounds {
+	minX, maxX := math.Min(c.Start.X, c.End.X), math.Max(c.Start.X, c.End.X)
+	minY, maxY := math.Min(c.Start.Y, c.End.Y), math.Max(c.Start.Y, c.End.Y)
+	b := Bounds{X: minX, Y: minY, Width: maxX - minX, Height: maxY - minY}
+
+	if c.StartMarker != MarkerNone || c.EndMarker != MarkerNone {
+		b = b.Inflate(c.markerSize() / 2)
+	}
+	return b
+}
+
+// Scale 相對於中心點縮放直線
+func (c *Connector) Scale(sx, sy float64, center Point) {
+	c.Start = Point{X: center.X + (c.Start.X-center.X)*sx, Y: center.Y + (c.Start.Y-center.Y)*sy}
+	c.End = Point{X: center.X + (c.End.X-center.X)*sx, Y: center.Y + (c.End.Y-center.Y)*sy}
+}
+
+// Rotate 以 pivot 為中心旋轉兩個端點
+func (c *Connector) Rotate(angle float64, pivot Point) {
+	c.Start = rotatePoint(c.Start, pivot, angle)
+	c.End = rotatePoint(c.End, pivot, angle)
+}
+
+// Delete 空實現，實際刪除操作在 CanvasManager 中處理
+func (c *Connector) Delete() {
+	// 空實現
+}
@@ -0,0 +1,63 @@
+package shape
+
+import (
+	"math"
+	"testing"
+
+	"canvas-demo/internal/canvas/render"
+)
+
+func TestConnectorShaft(t *testing.T) {
+	// 線寬 5 的標記大小為 20，圓點半徑為 20/3
+	style := Style{StrokeStyle: "#000000", LineWidth: 5}
+	tests := []struct {
+		name       string
+		start, end Marker
+		to         Point
+		want       [2]Point
+	}{
+		{"no markers", MarkerNone, MarkerNone, Point{X: 100}, [2]Point{{X: 0}, {X: 100}}},
+		{"bars", MarkerBar, MarkerBar, Point{X: 100}, [2]Point{{X: 0}, {X: 100}}},
+		{"arrow", MarkerNone, MarkerArrow, Point{X: 100}, [2]Point{{X: 0}, {X: 80}}},
+		{"dot and arrow", MarkerDot, MarkerArrow, Point{X: 100}, [2]Point{{X: 20.0 / 3}, {X: 80}}},
+		{"vertical", MarkerArrow, MarkerNone, Point{Y: -100}, [2]Point{{Y: -20}, {Y: -100}}},
+		// 比兩個箭頭短的直線不描繪線段
+		{"overlapping", MarkerArrow, MarkerArrow, Point{X: 30}, [2]Point{{X: 20}, {X: 20}}},
+		{"zero length", MarkerDot, MarkerDot, Point{}, [2]Point{{}, {}}},
+	}
+	for _, tt := range tests {
+		c := NewConnector(style, tt.start, tt.end)
+		c.SetEndpoints(Point{}, tt.to)
+		start, end := c.Shaft()
+		if !samePoints([]Point{start, end}, tt.want[:]) {
+			t.Errorf("%s: Shaft() = %v, %v; want %v", tt.name, start, end, tt.want)
+		}
+	}
+}
+
+// 線段在箭頭的底邊結束，不會從尖端透出
+func TestConnectorDrawShaft(t *testing.T) {
+	c := NewConnector(Style{StrokeStyle: "#000000", LineWidth: 5}, MarkerNone, MarkerArrow)
+	c.SetEndpoints(Point{}, Point{X: 100})
+	r := render.NewRecorder()
+	c.Draw(r)
+
+	var path []interface{}
+	for _, call := range r.Calls {
+		if call.Method == "stroke" {
+			break
+		}
+		if call.Method == "moveTo" || call.Method == "lineTo" {
+			path = append(path, call.Args...)
+		}
+	}
+	want := []float64{0, 0, 80, 0}
+	if len(path) != len(want) {
+		t.Fatalf("shaft path = %v, want %v", path, want)
+	}
+	for i, v := range path {
+		if math.Abs(v.(float64)-want[i]) > 1e-9 {
+			t.Fatalf("shaft path = %v, want %v", path, want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package shape
+
+import (
+	"canvas-demo/internal/canvas/render"
+)
+
+const (
+	controlSize        = 5.0             // 視覺上的控制點大小（螢幕像素）
+	controlHitArea     = controlSize * 4 // 增加點選範圍到視覺大小的4倍
+	rotateHandleOffset = 20.0            // 旋轉控制點與邊界框上緣的距離（螢幕像素）
+)
+
+// DrawBoundsControls 繪製邊界框、四個角的控制點與上方的旋轉控制點，
+// angle 為邊界框以自身中心旋轉的角度，多選時也用來繪製合併的邊界框
+func DrawBoundsControls(r render.Renderer, bounds Bounds, angle, scale float64) {
+	// 保存當前繪圖狀態
+	beginRotation(r, bounds.Center(), angle)
+
+	// 設置控制點樣式
+	r.SetFillStyle("#ffffff")
+	r.SetStrokeStyle("#000000")
+	r.SetLineWidth(screenLength(1, scale))
+
+	// 繪製邊界框
+	r.BeginPath()
+	r.Rect(bounds.X, bounds.Y, bounds.Width, bounds.Height)
+	r.Stroke()
+
+	// 繪製旋轉控制點與連接線
+	handle := rotateHandle(bounds, scale)
+	r.BeginPath()
+	r.MoveTo(handle.X, bounds.Y)
+	r.LineTo(handle.X, handle.Y)
+	r.Stroke()
+
+	// 繪製控制點
+	for _, cp := range append(cornerControls(bounds), controlPosition{Rotate, handle.X, handle.Y}) {
+		r.BeginPath()
+		r.Arc(cp.x, cp.y, screenLength(controlSize, scale), 0, 2*3.14159, false)
+		r.Fill()
+		r.Stroke()
+	}
+
+	// 恢復繪圖狀態
+	r.Restore()
+}
+
+// ControlsBounds 回傳邊界框加上控制點與旋轉控制點後可能涵蓋的範圍，用於局部重繪
+func ControlsBounds(bounds Bounds, scale float64) Bounds {
+	return bounds.Inflate(screenLength(rotateHandleOffset+controlSize+1, scale))
+}
+
+// HitBoundsControls 檢查是否點擊到旋轉後邊界框的控制點
+func HitBoundsControls(p Point, bounds Bounds, angle, scale float64) ControlPoint {
+	// 轉回未旋轉的座標系再比較
+	p = rotatePoint(p, bounds.Center(), -angle)
+
+	for _, cp := range cornerControls(bounds) {
+		if distance(p, Point{X: cp.x, Y: cp.y}) <= screenTolerance(controlHitArea, scale) {
+			return cp.point
+		}
+	}
+	if distance(p, rotateHandle(bounds, scale)) <= screenTolerance(controlHitArea, scale) {
+		return Rotate
+	}
+	return None
+}
+
+// controlPosition 是控制點與其位置
+type controlPosition struct {
+	point ControlPoint
+	x, y  float64
+}
+
+// cornerControls 回傳邊界框四個角的控制點位置
+func cornerControls(bounds Bounds) []controlPosition {
+	return []controlPosition{
+		{TopLeft, bounds.X, bounds.Y},
+		{TopRight, bounds.X + bounds.Width, bounds.Y},
+		{BottomLeft, bounds.X, bounds.Y + bounds.Height},
+		{BottomRight, bounds.X + bounds.Width, bounds.Y + bounds.Height},
+	}
+}
+
+// rotateHandle 回傳未旋轉時旋轉控制點的位置
+func rotateHandle(bounds Bounds, scale float64) Point {
+	return Point{X: bounds.X + bounds.Width/2, Y: bounds.Y - screenLength(rotateHandleOffset, scale)}
+}
@@ -0,0 +1,65 @@
+package shape
+
+import (
+	"math"
+
+	"canvas-demo/internal/canvas/render"
+)
+
+// Ellipse 表示內切於外框的橢圓
+type Ellipse struct {
+	frame
+}
+
+// NewEllipse 創建新的橢圓
+func NewEllipse(style Style) *Ellipse {
+	return &Ellipse{frame: frame{Style: style}}
+}
+
+// Clone 回傳橢圓的複製
+func (e *Ellipse) Clone() Shape {
+	clone := *e
+	return &clone
+}
+
+// Draw 繪製橢圓，旋轉由 Ellipse 的參數處理，只需保存繪圖狀態
+func (e *Ellipse) Draw(r render.Renderer) {
+	r.Save()
+	defer r.Restore()
+
+	c := e.center()
+	r.BeginPath()
+	r.Ellipse(c.X, c.Y, e.Width/2, e.Height/2, e.Rotation, 0, 2*math.Pi, false)
+	e.paint(r)
+}
+
+// Contains 檢查點是否在橢圓上：有填滿時包含內部，否則只檢查外框
+func (e *Ellipse) Contains(p Point, scale float64) bool {
+	c := e.center()
+	rx, ry := e.Width/2, e.Height/2
+	// 轉回未旋轉的座標系再比較
+	p = rotatePoint(p, c, -e.Rotation)
+
+	// 退化成線段的橢圓
+	if rx == 0 || ry == 0 {
+		return pointToLineDistance(p, Point{X: e.X, Y: e.Y}, Point{X: e.X + e.Width, Y: e.Y + e.Height}) <= screenTolerance(hitTolerance, scale)
+	}
+
+	dx, dy := p.X-c.X, p.Y-c.Y
+	k := math.Sqrt((dx*dx)/(rx*rx) + (dy*dy)/(ry*ry))
+	if e.Style.FillStyle != "" && k <= 1 {
+		return true
+	}
+	if k == 0 {
+		return false
+	}
+
+	// 以沿著中心射線方向與橢圓的交點近似最近點
+	nearest := Point{X: c.X + dx/k, Y: c.Y + dy/k}
+	return distance(p, nearest) <= screenTolerance(hitTolerance, scale)
+}
+
+// center 回傳橢圓中心
+func (e *Ellipse) center() Point {
+	return Point{X: e.X + e.Width/2, Y: e.Y + e.Height/2}
+}
@@ -0,0 +1,126 @@
+package shape
+
+import "math"
+
+// Touches 檢查以 center 為中心、半徑 radius 的圓是否碰到線段（已計入線寬）
+func (l *Line) Touches(center Point, radius float64) bool {
+	points := l.WorldPoints()
+	r := radius + l.Style.LineWidth/2
+	if len(points) == 1 {
+		return math.Hypot(points[0].X-center.X, points[0].Y-center.Y) <= r
+	}
+	for i := 1; i < len(points); i++ {
+		if _, _, hit := circleInterval(points[i-1], points[i], center, r); hit {
+			return true
+		}
+	}
+	return false
+}
+
+// erasedSimplifyTolerance 平滑筆畫擦去後的片段由切成折線的路徑組成，
+// 以這個容差移除多餘的點後再以原本的方式平滑
+const erasedSimplifyTolerance = 0.5
+
+// Erase 擦去線段中被以 center 為中心、半徑 radius 的圓蓋住的部分（已計入線寬），
+// 回傳剩下的片段。沒有擦到時 erased 為 false。
+// 片段以畫布座標儲存點，不帶旋轉角度；筆壓筆畫的片段保留內插後的筆壓，
+// 平滑筆畫的片段保留平滑方式
+func (l *Line) Erase(center Point, radius float64) (pieces []*Line, erased bool) {
+	if !l.Touches(center, radius) {
+		return nil, false
+	}
+
+	points := l.WorldPoints()
+	r := radius + l.Style.LineWidth/2
+
+	// 每個點的筆壓，沒有筆壓時全部為 0 且不會使用
+	pressures := make([]float64, len(points))
+	pressure := l.HasPressure()
+	if pressure {
+		_, at := l.flatten()
+		for i, a := range at {
+			pressures[i] = l.pressureAt(a)
+		}
+	}
+
+	piece := NewLine(l.Style)
+	add := func(p Point, pr float64) {
+		if pressure {
+			piece.AddPressurePoint(p, pr)
+		} else {
+			piece.AddPoint(p)
+		}
+	}
+	flush := func() {
+		if len(piece.Points) >= 2 {
+			piece.Pen = l.Pen
+			if l.Smoothing != SmoothNone {
+				piece.Smoothing = l.Smoothing
+				piece.Simplify(erasedSimplifyTolerance)
+			}
+			pieces = append(pieces, piece)
+		}
+		piece = NewLine(l.Style)
+	}
+
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		pa, pb := pressures[i-1], pressures[i]
+		t0, t1, hit := circleInterval(a, b, center, r)
+		if !hit {
+			if len(piece.Points) == 0 {
+				add(a, pa)
+			}
+			add(b, pb)
+			continue
+		}
+
+		// 保留進入圓之前與離開圓之後的部分
+		if t0 > 0 {
+			if len(piece.Points) == 0 {
+				add(a, pa)
+			}
+			add(lerp(a, b, t0), pa+(pb-pa)*t0)
+		}
+		flush()
+		if t1 < 1 {
+			add(lerp(a, b, t1), pa+(pb-pa)*t1)
+			add(b, pb)
+		}
+	}
+	flush()
+
+	return pieces, true
+}
+
+// circleInterval 回傳線段 ab 落在圓內的參數範圍 [t0, t1]（0 為 a，1 為 b）
+func circleInterval(a, b, center Point, r float64) (t0, t1 float64, hit bool) {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	fx, fy := a.X-center.X, a.Y-center.Y
+
+	qa := dx*dx + dy*dy
+	qb := 2 * (fx*dx + fy*dy)
+	qc := fx*fx + fy*fy - r*r
+
+	// 退化成一個點的線段
+	if qa == 0 {
+		return 0, 1, qc <= 0
+	}
+
+	disc := qb*qb - 4*qa*qc
+	if disc < 0 {
+		return 0, 0, false
+	}
+	s := math.Sqrt(disc)
+	t0 = (-qb - s) / (2 * qa)
+	t1 = (-qb + s) / (2 * qa)
+	if t1 < 0 || t0 > 1 {
+		return 0, 0, false
+	}
+	return math.Max(t0, 0), math.Min(t1, 1), true
+}
+
+// lerp 回傳 a 到 b 之間參數 t 的點
+func lerp(a, b Point, t float64) Point {
+	return Point{X: a.X + (b.X-a.X)*t, Y: a.Y + (b.Y-a.Y)*t}
+}
@@ -0,0 +1,175 @@
+package shape
+
+import (
+	"math"
+	"testing"
+)
+
+// samePoints 比較兩組點，容許浮點誤差
+func samePoints(a, b []Point) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i].X-b[i].X) > 1e-9 || math.Abs(a[i].Y-b[i].Y) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+// piecePoints 回傳每個片段的點
+func piecePoints(pieces []*Line) [][]Point {
+	points := make([][]Point, len(pieces))
+	for i, p := range pieces {
+		points[i] = p.Points
+	}
+	return points
+}
+
+func TestErase(t *testing.T) {
+	// 線寬 2，半徑 9 的橡皮擦擦去距離中心 10 以內的部分
+	horizontal := func() *Line { return newTestLine(Point{X: 0, Y: 0}, Point{X: 100, Y: 0}) }
+	rotated := horizontal()
+	rotated.Rotate(math.Pi/2, Point{X: 50, Y: 0})
+
+	tests := []struct {
+		name   string
+		line   *Line
+		center Point
+		radius float64
+		erased bool
+		want   [][]Point
+	}{
+		{"middle", horizontal(), Point{X: 50, Y: 0}, 9, true, [][]Point{
+			{{X: 0, Y: 0}, {X: 40, Y: 0}},
+			{{X: 60, Y: 0}, {X: 100, Y: 0}},
+		}},
+		{"start", horizontal(), Point{X: 0, Y: 0}, 9, true, [][]Point{
+			{{X: 10, Y: 0}, {X: 100, Y: 0}},
+		}},
+		{"end", horizontal(), Point{X: 105, Y: 0}, 9, true, [][]Point{
+			{{X: 0, Y: 0}, {X: 95, Y: 0}},
+		}},
+		{"everything", horizontal(), Point{X: 50, Y: 0}, 100, true, nil},
+		{"miss", horizontal(), Point{X: 50, Y: 20}, 9, false, nil},
+		{"corner", newTestLine(Point{X: 0, Y: 0}, Point{X: 50, Y: 0}, Point{X: 50, Y: 50}), Point{X: 50, Y: 0}, 9, true, [][]Point{
+			{{X: 0, Y: 0}, {X: 40, Y: 0}},
+			{{X: 50, Y: 10}, {X: 50, Y: 50}},
+		}},
+		{"single point", newTestLine(Point{X: 5, Y: 5}), Point{X: 5, Y: 5}, 1, true, nil},
+		{"single point miss", newTestLine(Point{X: 5, Y: 5}), Point{X: 50, Y: 5}, 1, false, nil},
+		// 旋轉的線段擦去後以畫布座標儲存
+		{"rotated", rotated, Point{X: 50, Y: 0}, 9, true, [][]Point{
+			{{X: 50, Y: -50}, {X: 50, Y: -10}},
+			{{X: 50, Y: 10}, {X: 50, Y: 50}},
+		}},
+	}
+	for _, tt := range tests {
+		pieces, erased := tt.line.Erase(tt.center, tt.radius)
+		if erased != tt.erased {
+			t.Errorf("%s: erased = %v, want %v", tt.name, erased, tt.erased)
+		}
+		got := piecePoints(pieces)
+		ok := len(got) == len(tt.want)
+		for i := 0; ok && i < len(got); i++ {
+			ok = samePoints(got[i], tt.want[i])
+		}
+		if !ok {
+			t.Errorf("%s: pieces = %v, want %v", tt.name, got, tt.want)
+		}
+		for _, p := range pieces {
+			if p.Rotation != 0 || p.Style != tt.line.Style {
+				t.Errorf("%s: piece has rotation %g and style %+v", tt.name, p.Rotation, p.Style)
+			}
+		}
+	}
+}
+
+func TestErasePressure(t *testing.T) {
+	l := NewLine(Style{StrokeStyle: "#000000", LineWidth: 2})
+	l.Pen = DefaultPenStyle
+	l.AddPressurePoint(Point{X: 0, Y: 0}, 0)
+	l.AddPressurePoint(Point{X: 100, Y: 0}, 1)
+
+	pieces, erased := l.Erase(Point{X: 50, Y: 0}, 9)
+	if !erased || len(pieces) != 2 {
+		t.Fatalf("Erase = %d pieces, %v; want 2 pieces", len(pieces), erased)
+	}
+	// 切開處的筆壓依位置內插
+	wants := [][]float64{{0, 0.4}, {0.6, 1}}
+	for i, p := range pieces {
+		if !p.HasPressure() || p.Pen != l.Pen {
+			t.Errorf("pieces[%d] lost its pressure or pen: %+v", i, p)
+			continue
+		}
+		for j, want := range wants[i] {
+			if math.Abs(p.Pressures[j]-want) > 1e-9 {
+				t.Errorf("pieces[%d].Pressures = %v, want %v", i, p.Pressures, wants[i])
+				break
+			}
+		}
+	}
+}
+
+func TestEraseSmoothed(t *testing.T) {
+	for _, smoothing := range []Smoothing{SmoothCatmullRom, SmoothQuadratic} {
+		l := NewLine(testStyle)
+		l.Smoothing = smoothing
+		for x := 0.0; x <= 400; x += 20 {
+			l.AddPoint(Point{X: x, Y: 40 * math.Sin(x/40)})
+		}
+
+		pieces, erased := l.Erase(Point{X: 200, Y: 40 * math.Sin(5)}, 10)
+		if !erased || len(pieces) != 2 {
+			t.Fatalf("%q: Erase = %d pieces, %v; want 2 pieces", smoothing, len(pieces), erased)
+		}
+		// 片段保留平滑方式，點數不會因為切成折線的路徑而大量增加
+		n := 0
+		for i, p := range pieces {
+			if p.Smoothing != smoothing {
+				t.Errorf("%q: pieces[%d].Smoothing = %q", smoothing, i, p.Smoothing)
+			}
+			for _, q := range p.Points {
+				if !l.Contains(q, 1) {
+					t.Errorf("%q: pieces[%d] point %v is not on the stroke", smoothing, i, q)
+				}
+			}
+			n += len(p.Points)
+		}
+		if n > 2*len(l.Points) {
+			t.Errorf("%q: pieces have %d points, stroke has %d (%d after flattening)", smoothing, n, len(l.Points), len(l.path()))
+		}
+	}
+}
+
+func TestCircleInterval(t *testing.T) {
+	a, b := Point{X: 0, Y: 0}, Point{X: 10, Y: 0}
+	tests := []struct {
+		name   string
+		center Point
+		r      float64
+		t0, t1 float64
+		hit    bool
+	}{
+		{"through the middle", Point{X: 5, Y: 0}, 2, 0.3, 0.7, true},
+		{"covers start", Point{X: 0, Y: 0}, 2, 0, 0.2, true},
+		{"covers everything", Point{X: 5, Y: 0}, 20, 0, 1, true},
+		{"beside the segment", Point{X: 5, Y: 3}, 2, 0, 0, false},
+		{"past the end", Point{X: 15, Y: 0}, 2, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t0, t1, hit := circleInterval(a, b, tt.center, tt.r)
+		if hit != tt.hit || math.Abs(t0-tt.t0) > 1e-9 || math.Abs(t1-tt.t1) > 1e-9 {
+			t.Errorf("%s: circleInterval = %g, %g, %v; want %g, %g, %v", tt.name, t0, t1, hit, tt.t0, tt.t1, tt.hit)
+		}
+	}
+
+	// 退化成一個點的線段
+	if _, _, hit := circleInterval(a, a, Point{X: 1, Y: 0}, 2); !hit {
+		t.Error("point inside the circle is not hit")
+	}
+	if _, _, hit := circleInterval(a, a, Point{X: 5, Y: 0}, 2); hit {
+		t.Error("point outside the circle is hit")
+	}
+}
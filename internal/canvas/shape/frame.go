@@ -0,0 +1,101 @@
+package shape
+
+import (
+	"math"
+
+	"canvas-demo/internal/canvas/render"
+)
+
+// frame 是以外框定義的形狀（矩形、橢圓）共用的部分
+type frame struct {
+	X        float64
+	Y        float64
+	Width    float64
+	Height   float64
+	Style    Style
+	Rotation float64 // 以外框中心旋轉的角度（弧度）
+}
+
+// SetCorners 以兩個對角點設置外框
+func (f *frame) SetCorners(a, b Point) {
+	f.X = math.Min(a.X, b.X)
+	f.Y = math.Min(a.Y, b.Y)
+	f.Width = math.Abs(b.X - a.X)
+	f.Height = math.Abs(b.Y - a.Y)
+}
+
+// Move 移動外框
+func (f *frame) Move(dx, dy float64) {
+	f.X += dx
+	f.Y += dy
+}
+
+// GetBounds 獲取外框旋轉後的邊界
+func (f *frame) GetBounds() Bounds {
+	return rotatedBounds(f.localBounds(), f.Rotation)
+}
+
+// OrientedBounds 回傳未旋轉時的外框與旋轉角度
+func (f *frame) OrientedBounds() (Bounds, float64) {
+	return f.localBounds(), f.Rotation
+}
+
+// localBounds 獲取未旋轉時的外框
+func (f *frame) localBounds() Bounds {
+	return Bounds{X: f.X, Y: f.Y, Width: f.Width, Height: f.Height}
+}
+
+// Scale 相對於中心點縮放外框
+func (f *frame) Scale(sx, sy float64, center Point) {
+	if f.Rotation != 0 {
+		// 在未旋轉的座標系中以自身中心縮放，再移到縮放後的位置
+		c := f.localBounds().Center()
+		dx, dy := scaledCenter(c, center, sx, sy)
+		f.scaleAround(sx, sy, c)
+		f.Move(dx, dy)
+		return
+	}
+	f.scaleAround(sx, sy, center)
+}
+
+// Rotate 以 pivot 為中心旋轉外框
+func (f *frame) Rotate(angle float64, pivot Point) {
+	f.Move(rotatedCenter(f.localBounds().Center(), pivot, angle))
+	f.Rotation = normalizeAngle(f.Rotation + angle)
+}
+
+// scaleAround 相對於中心點縮放未旋轉的外框
+func (f *frame) scaleAround(sx, sy float64, center Point) {
+	f.SetCorners(
+		Point{X: center.X + (f.X-center.X)*sx, Y: center.Y + (f.Y-center.Y)*sy},
+		Point{X: center.X + (f.X+f.Width-center.X)*sx, Y: center.Y + (f.Y+f.Height-center.Y)*sy},
+	)
+}
+
+// Delete 空實現，實際刪除操作在 CanvasManager 中處理
+func (f *frame) Delete() {
+	// 空實現
+}
+
+// DrawControls 繪製控制點
+func (f *frame) DrawControls(r render.Renderer, scale float64) {
+	DrawBoundsControls(r, f.localBounds(), f.Rotation, scale)
+}
+
+// HitControl 檢查是否點擊到控制點
+func (f *frame) HitControl(p Point, scale float64) ControlPoint {
+	return HitBoundsControls(p, f.localBounds(), f.Rotation, scale)
+}
+
+// paint 依樣式填滿並描繪目前的路徑
+func (f *frame) paint(r render.Renderer) {
+	if f.Style.FillStyle != "" {
+		r.SetFillStyle(f.Style.FillStyle)
+		r.Fill()
+	}
+	if f.Style.StrokeStyle != "" && f.Style.LineWidth > 0 {
+		r.SetStrokeStyle(f.Style.StrokeStyle)
+		r.SetLineWidth(f.Style.LineWidth)
+		r.Stroke()
+	}
+}
@@ -0,0 +1,94 @@
+package shape
+
+import (
+	"canvas-demo/internal/canvas/render"
+)
+
+// Group 將多個形狀組合成一個單位，所有操作都轉交給子形狀。群組可以巢狀
+type Group struct {
+	Children []Shape // 由下往上的繪製順序
+}
+
+// NewGroup 創建包含 children 的群組
+func NewGroup(children []Shape) *Group {
+	return &Group{Children: append([]Shape(nil), children...)}
+}
+
+// Clone 回傳群組的深層複製，子形狀也會一併複製
+func (g *Group) Clone() Shape {
+	children := make([]Shape, len(g.Children))
+	for i, c := range g.Children {
+		children[i] = c.Clone()
+	}
+	return &Group{Children: children}
+}
+
+// Draw 依序繪製子形狀
+func (g *Group) Draw(r render.Renderer) {
+	for _, c := range g.Children {
+		c.Draw(r)
+	}
+}
+
+// Contains 檢查點是否落在任一子形狀上
+func (g *Group) Contains(p Point, scale float64) bool {
+	for i := len(g.Children) - 1; i >= 0; i-- {
+		if g.Children[i].Contains(p, scale) {
+			return true
+		}
+	}
+	return false
+}
+
+// IntersectsRect 檢查任一子形狀是否與矩形範圍相交
+func (g *Group) IntersectsRect(r Bounds) bool {
+	for _, c := range g.Children {
+		if IntersectsRect(c, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// Move 移動所有子形狀
+func (g *Group) Move(dx, dy float64) {
+	for _, c := range g.Children {
+		c.Move(dx, dy)
+	}
+}
+
+// GetBounds 獲取所有子形狀的合併邊界
+func (g *Group) GetBounds() Bounds {
+	return UnionBounds(g.Children)
+}
+
+// Scale 以相同的中心點縮放所有子形狀
+func (g *Group) Scale(sx, sy float64, center Point) {
+	for _, c := range g.Children {
+		c.Scale(sx, sy, center)
+	}
+}
+
+// Rotate 以相同的中心點旋轉所有子形狀
+func (g *Group) Rotate(angle float64, pivot Point) {
+	for _, c := range g.Children {
+		c.Rotate(angle, pivot)
+	}
+}
+
+// Delete 刪除所有子形狀（例如移除文字的 HTML 輸入框）
+func (g *Group) Delete() {
+	for _, c := range g.Children {
+		c.Delete()
+	}
+}
+
+// DrawControls 繪製群組邊界框的控制點
+func (g *Group) DrawControls(r render.Renderer, scale float64) {
+	DrawBoundsControls(r, g.GetBounds(), 0, scale)
+}
+
+// HitControl 檢查是否點擊到群組邊界框的控制點
+func (g *Group) HitControl(p Point, scale float64) ControlPoint {
+	return HitBoundsControls(p, g.GetBounds(), 0, scale)
+}
@@ -0,0 +1,63 @@
+package shape
+
+import "math"
+
+// RectIntersecter 由能精確判斷是否與矩形範圍相交的形狀實作
+type RectIntersecter interface {
+	IntersectsRect(r Bounds) bool
+}
+
+// IntersectsRect 檢查形狀是否與矩形範圍相交，用於框選。
+// 未實作 RectIntersecter 的形狀以邊界判斷
+func IntersectsRect(s Shape, r Bounds) bool {
+	if !s.GetBounds().Intersects(r) {
+		return false
+	}
+	if ri, ok := s.(RectIntersecter); ok {
+		return ri.IntersectsRect(r)
+	}
+	return true
+}
+
+// IntersectsRect 檢查線段的任一段是否經過矩形範圍
+func (l *Line) IntersectsRect(r Bounds) bool {
+	return polylineIntersectsRect(l.WorldPoints(), r)
+}
+
+// IntersectsRect 檢查直線是否經過矩形範圍
+func (c *Connector) IntersectsRect(r Bounds) bool {
+	return segmentIntersectsRect(c.Start, c.End, r)
+}
+
+// polylineIntersectsRect 檢查折線是否經過矩形範圍
+func polylineIntersectsRect(points []Point, r Bounds) bool {
+	if len(points) == 1 {
+		return r.Encloses(Bounds{X: points[0].X, Y: points[0].Y})
+	}
+	for i := 1; i < len(points); i++ {
+		if segmentIntersectsRect(points[i-1], points[i], r) {
+			return true
+		}
+	}
+	return false
+}
+
+// segmentIntersectsRect 以 Liang–Barsky 裁切檢查線段 ab 是否經過矩形範圍
+func segmentIntersectsRect(a, b Point, r Bounds) bool {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	t0, t1 := 0.0, 1.0
+	clip := func(p, q float64) bool {
+		if p == 0 {
+			return q >= 0
+		}
+		t := q / p
+		if p < 0 {
+			t0 = math.Max(t0, t)
+		} else {
+			t1 = math.Min(t1, t)
+		}
+		return t0 <= t1
+	}
+	return clip(-dx, a.X-r.X) && clip(dx, r.X+r.Width-a.X) &&
+		clip(-dy, a.Y-r.Y) && clip(dy, r.Y+r.Height-a.Y)
+}
@@ -0,0 +1,187 @@
+package shape
+
+import (
+	"fmt"
+	"math"
+)
+
+// PenStyle 定義筆壓筆畫的寬度曲線
+type PenStyle struct {
+	MinWidth   float64 // 筆壓為 0 時的寬度
+	MaxWidth   float64 // 筆壓為 1 時的寬度
+	Thinning   float64 // 0 到 1，筆壓影響寬度的程度，0 表示固定為 MaxWidth
+	TaperStart float64 // 起點逐漸變細的長度，0 表示不變細
+	TaperEnd   float64 // 終點逐漸變細的長度，0 表示不變細
+}
+
+// DefaultPenStyle 是新的筆壓筆畫預設的寬度曲線
+var DefaultPenStyle = PenStyle{MinWidth: 1, MaxWidth: 6, Thinning: 0.6}
+
+// Validate 檢查寬度曲線的設定是否合理
+func (s PenStyle) Validate() error {
+	switch {
+	case s.MinWidth < 0 || s.MaxWidth <= 0 || s.MinWidth > s.MaxWidth:
+		return fmt.Errorf("invalid pen width range %g to %g", s.MinWidth, s.MaxWidth)
+	case s.Thinning < 0 || s.Thinning > 1:
+		return fmt.Errorf("invalid pen thinning %g", s.Thinning)
+	case s.TaperStart < 0 || s.TaperEnd < 0:
+		return fmt.Errorf("invalid pen taper %g, %g", s.TaperStart, s.TaperEnd)
+	}
+	return nil
+}
+
+// width 計算筆壓 pressure 對應的寬度
+func (s PenStyle) width(pressure float64) float64 {
+	pressure = math.Max(0, math.Min(1, pressure))
+	return s.MinWidth + (s.MaxWidth-s.MinWidth)*(1-s.Thinning*(1-pressure))
+}
+
+// capSteps 端點半圓切成的段數
+const capSteps = 8
+
+// HasPressure 檢查線段是否為每個點都有筆壓的可變寬度筆畫
+func (l *Line) HasPressure() bool {
+	return len(l.Pressures) > 0 && len(l.Pressures) == len(l.Points)
+}
+
+// AddPressurePoint 添加帶有筆壓（0 到 1）的點
+func (l *Line) AddPressurePoint(p Point, pressure float64) {
+	l.Points = append(l.Points, p)
+	l.Pressures = append(l.Pressures, pressure)
+}
+
+// samples 回傳繪製路徑上的點與每個點的寬度（未旋轉的座標）
+func (l *Line) samples() ([]Point, []float64) {
+	points, at := l.flatten()
+	widths := make([]float64, len(points))
+	for i, a := range at {
+		widths[i] = l.Pen.width(l.pressureAt(a))
+	}
+
+	// 起點與終點附近依沿路徑的距離逐漸變細
+	if l.Pen.TaperStart > 0 || l.Pen.TaperEnd > 0 {
+		lengths := make([]float64, len(points))
+		for i := 1; i < len(points); i++ {
+			lengths[i] = lengths[i-1] + distanceBetween(points[i-1], points[i])
+		}
+		total := lengths[len(lengths)-1]
+		for i, s := range lengths {
+			if l.Pen.TaperStart > 0 {
+				widths[i] *= math.Min(1, s/l.Pen.TaperStart)
+			}
+			if l.Pen.TaperEnd > 0 {
+				widths[i] *= math.Min(1, (total-s)/l.Pen.TaperEnd)
+			}
+		}
+	}
+	return points, widths
+}
+
+// pressureAt 內插 Points 中位置 at（可以有小數）的筆壓
+func (l *Line) pressureAt(at float64) float64 {
+	i := int(at)
+	if i >= len(l.Pressures)-1 {
+		return l.Pressures[len(l.Pressures)-1]
+	}
+	t := at - float64(i)
+	return l.Pressures[i]*(1-t) + l.Pressures[i+1]*t
+}
+
+// Outline 回傳可變寬度筆畫填滿用的外框多邊形（未旋轉的座標），
+// 由左側的邊、終點的半圓、右側的邊與起點的半圓組成
+func (l *Line) Outline() []Point {
+	if !l.HasPressure() {
+		return nil
+	}
+	points, widths := l.samples()
+	points, widths = dedupeSamples(points, widths)
+
+	if len(points) == 1 {
+		return circlePolygon(points[0], widths[0]/2)
+	}
+
+	n := len(points)
+	normals := make([]Point, n)
+	for i := range points {
+		// 以前後兩點的方向作為切線，取得左側的法向量
+		a, b := points[max(i-1, 0)], points[min(i+1, n-1)]
+		d := distanceBetween(a, b)
+		if d == 0 {
+			// 筆畫折返時前後兩點重合，改用前一段的方向
+			a, b = points[i-1], points[i]
+			d = distanceBetween(a, b)
+		}
+		normals[i] = Point{X: -(b.Y - a.Y) / d, Y: (b.X - a.X) / d}
+	}
+
+	outline := make([]Point, 0, 2*n+2*capSteps)
+	for i, p := range points {
+		outline = append(outline, offsetPoint(p, normals[i], widths[i]/2))
+	}
+	outline = appendCap(outline, points[n-1], normals[n-1], widths[n-1]/2)
+	for i := n - 1; i >= 0; i-- {
+		outline = append(outline, offsetPoint(points[i], normals[i], -widths[i]/2))
+	}
+	outline = appendCap(outline, points[0], Point{X: -normals[0].X, Y: -normals[0].Y}, widths[0]/2)
+	return outline
+}
+
+// dedupeSamples 移除與前一個點重合的點，避免無法計算方向
+func dedupeSamples(points []Point, widths []float64) ([]Point, []float64) {
+	outPoints := []Point{points[0]}
+	outWidths := []float64{widths[0]}
+	for i := 1; i < len(points); i++ {
+		if points[i] == outPoints[len(outPoints)-1] {
+			outWidths[len(outWidths)-1] = math.Max(outWidths[len(outWidths)-1], widths[i])
+			continue
+		}
+		outPoints = append(outPoints, points[i])
+		outWidths = append(outWidths, widths[i])
+	}
+	return outPoints, outWidths
+}
+
+// offsetPoint 回傳 p 沿法向量 normal 移動 d 的點
+func offsetPoint(p, normal Point, d float64) Point {
+	return Point{X: p.X + normal.X*d, Y: p.Y + normal.Y*d}
+}
+
+// appendCap 加入從 center+normal*r 繞到 center-normal*r 的半圓（不含兩端），
+// 經過法向量順時針轉 90° 的方向
+func appendCap(outline []Point, center, normal Point, r float64) []Point {
+	start := math.Atan2(normal.Y, normal.X)
+	for k := 1; k < capSteps; k++ {
+		a := start - math.Pi*float64(k)/capSteps
+		outline = append(outline, Point{X: center.X + r*math.Cos(a), Y: center.Y + r*math.Sin(a)})
+	}
+	return outline
+}
+
+// circlePolygon 回傳近似圓形的多邊形
+func circlePolygon(center Point, r float64) []Point {
+	points := make([]Point, 2*capSteps)
+	for k := range points {
+		a := math.Pi * float64(k) / capSteps
+		points[k] = Point{X: center.X + r*math.Cos(a), Y: center.Y + r*math.Sin(a)}
+	}
+	return points
+}
+
+// outlineContains 檢查點是否在外框多邊形內或靠近外框（非零環繞規則）
+func outlineContains(outline []Point, p Point, scale float64) bool {
+	winding := 0
+	for i := range outline {
+		a, b := outline[i], outline[(i+1)%len(outline)]
+		if pointToLineDistance(p, a, b) <= screenTolerance(hitTolerance, scale) {
+			return true
+		}
+		cross := (b.X-a.X)*(p.Y-a.Y) - (p.X-a.X)*(b.Y-a.Y)
+		switch {
+		case a.Y <= p.Y && b.Y > p.Y && cross > 0:
+			winding++
+		case a.Y > p.Y && b.Y <= p.Y && cross < 0:
+			winding--
+		}
+	}
+	return winding != 0
+}
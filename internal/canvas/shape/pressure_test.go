@@ -0,0 +1,55 @@
+package shape
+
+import (
+	"math"
+	"testing"
+)
+
+// 筆畫折返到前一個點（A→B→A）時外框與邊界仍然是有限的數值
+func TestOutlineBackAndForth(t *testing.T) {
+	for _, smoothing := range []Smoothing{SmoothNone, SmoothCatmullRom, SmoothQuadratic} {
+		l := NewLine(Style{StrokeStyle: "#000000", LineWidth: DefaultPenStyle.MaxWidth})
+		l.Pen = DefaultPenStyle
+		l.Smoothing = smoothing
+		l.AddPressurePoint(Point{X: 10, Y: 10}, 0.5)
+		l.AddPressurePoint(Point{X: 20, Y: 10}, 0.5)
+		l.AddPressurePoint(Point{X: 10, Y: 10}, 0.5)
+
+		for i, p := range l.Outline() {
+			if !finite(p.X) || !finite(p.Y) {
+				t.Fatalf("%q: outline[%d] = %v", smoothing, i, p)
+			}
+		}
+		b := l.GetBounds()
+		if !finite(b.X) || !finite(b.Y) || !finite(b.Width) || !finite(b.Height) {
+			t.Fatalf("%q: GetBounds() = %v", smoothing, b)
+		}
+		if l.Contains(Point{X: 500, Y: 500}, 1) {
+			t.Errorf("%q: Contains far point", smoothing)
+		}
+		if !l.Contains(Point{X: 15, Y: 10}, 1) {
+			t.Errorf("%q: does not contain point on the stroke", smoothing)
+		}
+	}
+}
+
+func TestPenStyleWidth(t *testing.T) {
+	s := PenStyle{MinWidth: 1, MaxWidth: 5, Thinning: 1}
+	tests := []struct {
+		pressure, want float64
+	}{
+		{0, 1},
+		{0.5, 3},
+		{1, 5},
+		{2, 5}, // 超出範圍的筆壓視為 1
+	}
+	for _, tt := range tests {
+		if got := s.width(tt.pressure); got != tt.want {
+			t.Errorf("width(%g) = %g, want %g", tt.pressure, got, tt.want)
+		}
+	}
+}
+
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
@@ -0,0 +1,56 @@
+package shape
+
+import (
+	"canvas-demo/internal/canvas/render"
+)
+
+// Rect 表示矩形
+type Rect struct {
+	frame
+}
+
+// NewRect 創建新的矩形
+func NewRect(style Style) *Rect {
+	return &Rect{frame: frame{Style: style}}
+}
+
+// Clone 回傳矩形的複製
+func (rc *Rect) Clone() Shape {
+	clone := *rc
+	return &clone
+}
+
+// Draw 繪製矩形
+func (rc *Rect) Draw(r render.Renderer) {
+	beginRotation(r, rc.localBounds().Center(), rc.Rotation)
+	defer r.Restore()
+
+	r.BeginPath()
+	r.Rect(rc.X, rc.Y, rc.Width, rc.Height)
+	rc.paint(r)
+}
+
+// Contains 檢查點是否在矩形上：有填滿時包含內部，否則只檢查外框
+func (rc *Rect) Contains(p Point, scale float64) bool {
+	// 轉回未旋轉的座標系再比較
+	p = rotatePoint(p, rc.localBounds().Center(), -rc.Rotation)
+
+	if rc.Style.FillStyle != "" &&
+		p.X >= rc.X && p.X <= rc.X+rc.Width &&
+		p.Y >= rc.Y && p.Y <= rc.Y+rc.Height {
+		return true
+	}
+
+	corners := []Point{
+		{X: rc.X, Y: rc.Y},
+		{X: rc.X + rc.Width, Y: rc.Y},
+		{X: rc.X + rc.Width, Y: rc.Y + rc.Height},
+		{X: rc.X, Y: rc.Y + rc.Height},
+	}
+	for i := range corners {
+		if pointToLineDistance(p, corners[i], corners[(i+1)%4]) <= screenTolerance(hitTolerance, scale) {
+			return true
+		}
+	}
+	return false
+}
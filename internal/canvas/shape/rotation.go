@@ -0,0 +1,143 @@
+package shape
+
+import (
+	"math"
+
+	"canvas-demo/internal/canvas/render"
+)
+
+// Oriented 由可以旋轉的形狀實作，回傳未旋轉時的外框與旋轉角度（弧度）。
+// 形狀以外框中心為旋轉中心。
+type Oriented interface {
+	OrientedBounds() (Bounds, float64)
+}
+
+// Rotation 回傳形狀目前的旋轉角度，不支援旋轉角度的形狀回傳 0
+func Rotation(s Shape) float64 {
+	if o, ok := s.(Oriented); ok {
+		_, angle := o.OrientedBounds()
+		return angle
+	}
+	return 0
+}
+
+// ControlPosition 回傳控制點在畫布座標中的位置（已套用旋轉），
+// scale 為畫面的縮放倍率，決定旋轉控制點的位置
+func ControlPosition(s Shape, cp ControlPoint, scale float64) Point {
+	b, angle := s.GetBounds(), 0.0
+	if o, ok := s.(Oriented); ok {
+		b, angle = o.OrientedBounds()
+	}
+	return BoundsControlPosition(b, angle, cp, scale)
+}
+
+// BoundsControlPosition 回傳以自身中心旋轉 angle 的邊界框上控制點的位置
+func BoundsControlPosition(b Bounds, angle float64, cp ControlPoint, scale float64) Point {
+	var p Point
+	switch cp {
+	case TopLeft:
+		p = Point{X: b.X, Y: b.Y}
+	case TopRight:
+		p = Point{X: b.X + b.Width, Y: b.Y}
+	case BottomLeft:
+		p = Point{X: b.X, Y: b.Y + b.Height}
+	case BottomRight:
+		p = Point{X: b.X + b.Width, Y: b.Y + b.Height}
+	case Rotate:
+		p = rotateHandle(b, scale)
+	default:
+		p = b.Center()
+	}
+	return rotatePoint(p, b.Center(), angle)
+}
+
+// Opposite 回傳對角的控制點，用於決定縮放中心
+func (cp ControlPoint) Opposite() ControlPoint {
+	switch cp {
+	case TopLeft:
+		return BottomRight
+	case TopRight:
+		return BottomLeft
+	case BottomLeft:
+		return TopRight
+	case BottomRight:
+		return TopLeft
+	default:
+		return None
+	}
+}
+
+// rotatePoint 將 p 以 center 為中心旋轉 angle 弧度
+func rotatePoint(p, center Point, angle float64) Point {
+	if angle == 0 {
+		return p
+	}
+	cos, sin := math.Cos(angle), math.Sin(angle)
+	dx, dy := p.X-center.X, p.Y-center.Y
+	return Point{
+		X: center.X + dx*cos - dy*sin,
+		Y: center.Y + dx*sin + dy*cos,
+	}
+}
+
+// rotatedBounds 回傳外框以自身中心旋轉後的軸對齊邊界
+func rotatedBounds(b Bounds, angle float64) Bounds {
+	if angle == 0 {
+		return b
+	}
+	c := b.Center()
+	return pointsBounds([]Point{
+		rotatePoint(Point{X: b.X, Y: b.Y}, c, angle),
+		rotatePoint(Point{X: b.X + b.Width, Y: b.Y}, c, angle),
+		rotatePoint(Point{X: b.X, Y: b.Y + b.Height}, c, angle),
+		rotatePoint(Point{X: b.X + b.Width, Y: b.Y + b.Height}, c, angle),
+	})
+}
+
+// pointsBounds 回傳包含所有點的邊界
+func pointsBounds(points []Point) Bounds {
+	if len(points) == 0 {
+		return Bounds{}
+	}
+	minX, minY := points[0].X, points[0].Y
+	maxX, maxY := minX, minY
+	for _, p := range points[1:] {
+		minX, maxX = math.Min(minX, p.X), math.Max(maxX, p.X)
+		minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+	}
+	return Bounds{X: minX, Y: minY, Width: maxX - minX, Height: maxY - minY}
+}
+
+// normalizeAngle 將角度限制在 (-π, π]
+func normalizeAngle(angle float64) float64 {
+	angle = math.Mod(angle, 2*math.Pi)
+	if angle > math.Pi {
+		angle -= 2 * math.Pi
+	} else if angle <= -math.Pi {
+		angle += 2 * math.Pi
+	}
+	return angle
+}
+
+// rotatedCenter 計算形狀以 pivot 旋轉 angle 後，其中心需要移動的量
+func rotatedCenter(center, pivot Point, angle float64) (dx, dy float64) {
+	c := rotatePoint(center, pivot, angle)
+	return c.X - center.X, c.Y - center.Y
+}
+
+// scaledCenter 計算有旋轉角度的形狀縮放後中心需要移動的量：
+// 形狀先在未旋轉的座標系中以自身中心縮放，再平移到縮放後的中心
+func scaledCenter(c, center Point, sx, sy float64) (dx, dy float64) {
+	return (center.X + (c.X-center.X)*sx) - c.X, (center.Y + (c.Y-center.Y)*sy) - c.Y
+}
+
+// beginRotation 保存繪圖狀態並以 center 為中心旋轉座標系，需搭配 r.Restore()
+func beginRotation(r render.Renderer, center Point, angle float64) {
+	r.Save()
+	if angle == 0 {
+		return
+	}
+	r.Translate(center.X, center.Y)
+	r.Rotate(angle)
+	r.Translate(-center.X, -center.Y)
+}
@@ -1,9 +1,9 @@
-//go:build js && wasm
-
 package shape
 
 import (
-	"syscall/js"
+	"math"
+
+	"canvas-demo/internal/canvas/render"
 )
 
 // Point 表示座標點
@@ -12,6 +12,9 @@ type Point struct {
 	Y float64
 }
 
+// hitTolerance 選取容差（螢幕像素）
+const hitTolerance = 5.0
+
 // ControlPoint 類型定義控制點的位置
 type ControlPoint int
 
@@ -22,18 +25,22 @@ const (
 	BottomLeft
 	BottomRight
 	Rotate
+	StartPoint // 直線的起點
+	EndPoint   // 直線的終點
 )
 
 // Shape 定義基本形狀介面
 type Shape interface {
-	Draw(ctx js.Value)
-	Contains(p Point) bool
+	Draw(r render.Renderer)
+	Contains(p Point, scale float64) bool // scale 為畫面的縮放倍率，用來換算點選容差
 	Move(dx, dy float64)
 	GetBounds() Bounds
 	Scale(sx, sy float64, center Point)
+	Rotate(angle float64, pivot Point)
+	Clone() Shape
 	Delete()
-	DrawControls(ctx js.Value)
-	HitControl(p Point) ControlPoint
+	DrawControls(r render.Renderer, scale float64)
+	HitControl(p Point, scale float64) ControlPoint
 }
 
 // Bounds 表示形狀的邊界
@@ -44,17 +51,64 @@ type Bounds struct {
 	Height float64
 }
 
+// Center 回傳邊界的中心點
+func (b Bounds) Center() Point {
+	return Point{X: b.X + b.Width/2, Y: b.Y + b.Height/2}
+}
+
+// Union 回傳同時包含兩個邊界的最小邊界
+func (b Bounds) Union(o Bounds) Bounds {
+	minX := math.Min(b.X, o.X)
+	minY := math.Min(b.Y, o.Y)
+	maxX := math.Max(b.X+b.Width, o.X+o.Width)
+	maxY := math.Max(b.Y+b.Height, o.Y+o.Height)
+	return Bounds{X: minX, Y: minY, Width: maxX - minX, Height: maxY - minY}
+}
+
+// Inflate 回傳向四周擴張 d 的邊界
+func (b Bounds) Inflate(d float64) Bounds {
+	return Bounds{X: b.X - d, Y: b.Y - d, Width: b.Width + 2*d, Height: b.Height + 2*d}
+}
+
+// Intersects 檢查兩個邊界是否重疊（包含只有邊緣接觸的情況）
+func (b Bounds) Intersects(o Bounds) bool {
+	return b.X <= o.X+o.Width && o.X <= b.X+b.Width &&
+		b.Y <= o.Y+o.Height && o.Y <= b.Y+b.Height
+}
+
+// Encloses 檢查 o 是否完全位於邊界內
+func (b Bounds) Encloses(o Bounds) bool {
+	return o.X >= b.X && o.X+o.Width <= b.X+b.Width &&
+		o.Y >= b.Y && o.Y+o.Height <= b.Y+b.Height
+}
+
+// UnionBounds 回傳包含所有形狀的邊界，沒有形狀時回傳零值
+func UnionBounds(shapes []Shape) Bounds {
+	if len(shapes) == 0 {
+		return Bounds{}
+	}
+	b := shapes[0].GetBounds()
+	for _, s := range shapes[1:] {
+		b = b.Union(s.GetBounds())
+	}
+	return b
+}
+
 // Line 表示線段
 type Line struct {
-	Points     []Point
-	Style      Style
-	isSelected bool
+	Points    []Point
+	Style     Style
+	Rotation  float64   // 以外框中心旋轉的角度（弧度）
+	Smoothing Smoothing // 繪製時是否以曲線連接各點
+	Pressures []float64 // 每個點的筆壓（0 到 1），有筆壓時依 Pen 以填滿的外框繪製
+	Pen       PenStyle  // 筆壓筆畫的寬度曲線
 }
 
 // Style 定義形狀的樣式
 type Style struct {
-	StrokeStyle string
+	StrokeStyle string // 線條顏色，空字串表示不描邊
 	LineWidth   float64
+	FillStyle   string // 填滿顏色，空字串表示不填滿（線段不使用）
 }
 
 // NewLine 創建新的線段
@@ -65,102 +119,129 @@ func NewLine(style Style) *Line {
 	}
 }
 
+// Clone 回傳線段的深層複製
+func (l *Line) Clone() Shape {
+	return &Line{
+		Points:    append(make([]Point, 0, len(l.Points)), l.Points...),
+		Style:     l.Style,
+		Rotation:  l.Rotation,
+		Smoothing: l.Smoothing,
+		Pressures: append([]float64(nil), l.Pressures...),
+		Pen:       l.Pen,
+	}
+}
+
 // AddPoint 添加點到線段
 func (l *Line) AddPoint(p Point) {
 	l.Points = append(l.Points, p)
 }
 
 // Draw 繪製線段
-func (l *Line) Draw(ctx js.Value) {
+func (l *Line) Draw(r render.Renderer) {
 	if len(l.Points) < 2 {
 		return
 	}
 
-	ctx.Set("strokeStyle", l.Style.StrokeStyle)
-	ctx.Set("lineWidth", l.Style.LineWidth)
-
-	ctx.Call("beginPath")
-	ctx.Call("moveTo", l.Points[0].X, l.Points[0].Y)
-
-	for i := 1; i < len(l.Points); i++ {
-		ctx.Call("lineTo", l.Points[i].X, l.Points[i].Y)
+	beginRotation(r, l.localBounds().Center(), l.Rotation)
+	defer r.Restore()
+
+	if l.HasPressure() {
+		// 可變寬度的筆畫以外框多邊形填滿
+		outline := l.Outline()
+		r.SetFillStyle(l.Style.StrokeStyle)
+		r.BeginPath()
+		r.MoveTo(outline[0].X, outline[0].Y)
+		for _, p := range outline[1:] {
+			r.LineTo(p.X, p.Y)
+		}
+		r.ClosePath()
+		r.Fill()
+		return
 	}
 
-	ctx.Call("stroke")
+	r.SetStrokeStyle(l.Style.StrokeStyle)
+	r.SetLineWidth(l.Style.LineWidth)
+
+	r.BeginPath()
+	l.tracePath(r)
+	r.Stroke()
+}
 
-	if l.isSelected {
-		l.DrawControls(ctx)
+// DrawSegments 以直線段繪製從第 from 個點之後新增的部分，接點補上圓形，
+// 讓繪製中的筆畫每次只需要畫新的線段。不套用平滑、旋轉與筆畫兩端變細，完成後再以 Draw 重畫
+func (l *Line) DrawSegments(r render.Renderer, from int) {
+	r.Save()
+	defer r.Restore()
+	r.SetStrokeStyle(l.Style.StrokeStyle)
+	r.SetFillStyle(l.Style.StrokeStyle)
+
+	for i := max(from, 0) + 1; i < len(l.Points); i++ {
+		a, b := l.Points[i-1], l.Points[i]
+		width := l.Style.LineWidth
+		if l.HasPressure() {
+			width = l.Pen.width(l.Pressures[i])
+		}
+		r.SetLineWidth(width)
+		r.BeginPath()
+		r.MoveTo(a.X, a.Y)
+		r.LineTo(b.X, b.Y)
+		r.Stroke()
+		r.BeginPath()
+		r.Arc(b.X, b.Y, width/2, 0, 2*math.Pi, false)
+		r.Fill()
 	}
 }
 
 // DrawControls 繪製控制點
-func (l *Line) DrawControls(ctx js.Value) {
-	bounds := l.GetBounds()
-
-	// 保存當前繪圖狀態
-	ctx.Call("save")
-
-	// 設置控制點樣式
-	ctx.Set("fillStyle", "#ffffff")
-	ctx.Set("strokeStyle", "#000000")
-	ctx.Set("lineWidth", 1)
-
-	// 繪製邊界框
-	ctx.Call("beginPath")
-	ctx.Call("rect", bounds.X, bounds.Y, bounds.Width, bounds.Height)
-	ctx.Call("stroke")
-
-	const controlSize = 5.0 // 增加控制點大小
-
-	// 繪製控制點
-	controlPoints := []struct {
-		x, y float64
-	}{
-		{bounds.X, bounds.Y},                                // TopLeft
-		{bounds.X + bounds.Width, bounds.Y},                 // TopRight
-		{bounds.X, bounds.Y + bounds.Height},                // BottomLeft
-		{bounds.X + bounds.Width, bounds.Y + bounds.Height}, // BottomRight
-	}
+func (l *Line) DrawControls(r render.Renderer, scale float64) {
+	DrawBoundsControls(r, l.localBounds(), l.Rotation, scale)
+}
 
-	for _, cp := range controlPoints {
-		ctx.Call("beginPath")
-		ctx.Call("arc", cp.x, cp.y, controlSize, 0, 2*3.14159, false)
-		ctx.Call("fill")
-		ctx.Call("stroke")
+// HitControl 檢查是否點擊到控制點
+func (l *Line) HitControl(p Point, scale float64) ControlPoint {
+	return HitBoundsControls(p, l.localBounds(), l.Rotation, scale)
+}
+
+// Scale 縮放線段
+func (l *Line) Scale(sx, sy float64, center Point) {
+	if l.Rotation != 0 {
+		// 在未旋轉的座標系中以自身中心縮放，再移到縮放後的位置
+		c := l.localBounds().Center()
+		dx, dy := scaledCenter(c, center, sx, sy)
+		l.scalePoints(sx, sy, c)
+		l.Move(dx, dy)
+		return
 	}
+	l.scalePoints(sx, sy, center)
+}
 
-	// 恢復繪圖狀態
-	ctx.Call("restore")
+// Rotate 以 pivot 為中心旋轉線段
+func (l *Line) Rotate(angle float64, pivot Point) {
+	l.Move(rotatedCenter(l.localBounds().Center(), pivot, angle))
+	l.Rotation = normalizeAngle(l.Rotation + angle)
 }
 
-// HitControl 檢查是否點擊到控制點
-func (l *Line) HitControl(p Point) ControlPoint {
-	bounds := l.GetBounds()
-	const controlSize = 5.0         // 視覺上的控制點大小
-	const hitArea = controlSize * 4 // 增加點選範圍到視覺大小的4倍
-
-	// 檢查各個控制點
-	controlPoints := []struct {
-		point ControlPoint
-		x, y  float64
-	}{
-		{TopLeft, bounds.X, bounds.Y},
-		{TopRight, bounds.X + bounds.Width, bounds.Y},
-		{BottomLeft, bounds.X, bounds.Y + bounds.Height},
-		{BottomRight, bounds.X + bounds.Width, bounds.Y + bounds.Height},
-	}
+// OrientedBounds 回傳未旋轉時的外框與旋轉角度
+func (l *Line) OrientedBounds() (Bounds, float64) {
+	return l.localBounds(), l.Rotation
+}
 
-	for _, cp := range controlPoints {
-		if distance(p, Point{X: cp.x, Y: cp.y}) <= hitArea {
-			return cp.point
-		}
+// WorldPoints 回傳套用旋轉後的繪製路徑，平滑的曲線會被切成折線
+func (l *Line) WorldPoints() []Point {
+	path := l.path()
+	if l.Rotation == 0 {
+		return path
 	}
-
-	return None
+	c := l.localBounds().Center()
+	points := make([]Point, len(path))
+	for i, p := range path {
+		points[i] = rotatePoint(p, c, l.Rotation)
+	}
+	return points
 }
 
-// Scale 縮放線段
-func (l *Line) Scale(sx, sy float64, center Point) {
+// scalePoints 相對於中心點縮放所有點
+func (l *Line) scalePoints(sx, sy float64, center Point) {
 	for i := range l.Points {
 		// 相對於中心點進行縮放
 		dx := l.Points[i].X - center.X
@@ -176,22 +257,22 @@ func (l *Line) Delete() {
 	// 空實現
 }
 
-// SetSelected 設置選中狀態
-func (l *Line) SetSelected(selected bool) {
-	l.isSelected = selected
-}
-
 // Contains 檢查點是否在線段上
-func (l *Line) Contains(p Point) bool {
-	const threshold = 5.0 // 選取容差
+func (l *Line) Contains(p Point, scale float64) bool {
+	// 轉回未旋轉的座標系再比較
+	p = rotatePoint(p, l.localBounds().Center(), -l.Rotation)
+	if l.HasPressure() {
+		return outlineContains(l.Outline(), p, scale)
+	}
 
-	for i := 1; i < len(l.Points); i++ {
-		p1 := l.Points[i-1]
-		p2 := l.Points[i]
+	path := l.path()
+	for i := 1; i < len(path); i++ {
+		p1 := path[i-1]
+		p2 := path[i]
 
 		// 計算點到線段的距離
 		d := pointToLineDistance(p, p1, p2)
-		if d <= threshold {
+		if d <= screenTolerance(hitTolerance, scale) {
 			return true
 		}
 	}
@@ -206,18 +287,39 @@ func (l *Line) Move(dx, dy float64) {
 	}
 }
 
-// GetBounds 獲取線段的邊界
+// GetBounds 獲取線段旋轉後的邊界
 func (l *Line) GetBounds() Bounds {
-	if len(l.Points) == 0 {
+	if l.Rotation == 0 {
+		return l.localBounds()
+	}
+	if l.HasPressure() {
+		c := l.localBounds().Center()
+		outline := l.Outline()
+		for i, p := range outline {
+			outline[i] = rotatePoint(p, c, l.Rotation)
+		}
+		return pointsBounds(outline)
+	}
+	return pointsBounds(l.WorldPoints())
+}
+
+// localBounds 獲取線段未旋轉時的邊界（依實際繪製的路徑或外框）
+func (l *Line) localBounds() Bounds {
+	if l.HasPressure() {
+		return pointsBounds(l.Outline())
+	}
+
+	path := l.path()
+	if len(path) == 0 {
 		return Bounds{}
 	}
 
-	minX := l.Points[0].X
-	minY := l.Points[0].Y
-	maxX := l.Points[0].X
-	maxY := l.Points[0].Y
+	minX := path[0].X
+	minY := path[0].Y
+	maxX := path[0].X
+	maxY := path[0].Y
 
-	for _, p := range l.Points {
+	for _, p := range path {
 		if p.X < minX {
 			minX = p.X
 		}
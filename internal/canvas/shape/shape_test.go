@@ -0,0 +1,193 @@
+package shape
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"canvas-demo/internal/canvas/render"
+)
+
+var testStyle = Style{StrokeStyle: "#000000", LineWidth: 2}
+
+// newTestLine 建立以直線段連接各點的線段
+func newTestLine(points ...Point) *Line {
+	l := NewLine(testStyle)
+	for _, p := range points {
+		l.AddPoint(p)
+	}
+	return l
+}
+
+// newTestRect 建立左上角在 (x, y)、大小為 w×h 的矩形
+func newTestRect(style Style, x, y, w, h float64) *Rect {
+	rc := NewRect(style)
+	rc.SetCorners(Point{X: x, Y: y}, Point{X: x + w, Y: y + h})
+	return rc
+}
+
+func sameBounds(a, b Bounds) bool {
+	const eps = 1e-9
+	return math.Abs(a.X-b.X) < eps && math.Abs(a.Y-b.Y) < eps &&
+		math.Abs(a.Width-b.Width) < eps && math.Abs(a.Height-b.Height) < eps
+}
+
+func TestPointToLineDistance(t *testing.T) {
+	// 回傳的是距離的平方
+	tests := []struct {
+		name       string
+		p, a, b    Point
+		wantSquare float64
+	}{
+		{"above segment", Point{X: 5, Y: 3}, Point{}, Point{X: 10}, 9},
+		{"before start", Point{X: -3, Y: 4}, Point{}, Point{X: 10}, 25},
+		{"after end", Point{X: 13, Y: -4}, Point{}, Point{X: 10}, 25},
+		{"on segment", Point{X: 4}, Point{}, Point{X: 10}, 0},
+		{"degenerate segment", Point{X: 3, Y: 4}, Point{}, Point{}, 25},
+	}
+	for _, tt := range tests {
+		if got := pointToLineDistance(tt.p, tt.a, tt.b); math.Abs(got-tt.wantSquare) > 1e-9 {
+			t.Errorf("%s: pointToLineDistance = %g, want %g", tt.name, got, tt.wantSquare)
+		}
+	}
+}
+
+func TestGetBounds(t *testing.T) {
+	rotated := newTestRect(testStyle, 0, 0, 20, 10)
+	rotated.Rotate(math.Pi/2, Point{X: 10, Y: 5})
+
+	tests := []struct {
+		name  string
+		shape Shape
+		want  Bounds
+	}{
+		{"line", newTestLine(Point{X: 10, Y: 20}, Point{X: 30, Y: 5}, Point{X: 0, Y: 15}), Bounds{X: 0, Y: 5, Width: 30, Height: 15}},
+		{"rect", newTestRect(testStyle, 5, 6, 20, 10), Bounds{X: 5, Y: 6, Width: 20, Height: 10}},
+		{"rotated rect", rotated, Bounds{X: 5, Y: -5, Width: 10, Height: 20}},
+		{"group", NewGroup([]Shape{
+			newTestRect(testStyle, 0, 0, 10, 10),
+			newTestLine(Point{X: 20, Y: 30}, Point{X: 40, Y: 50}),
+		}), Bounds{X: 0, Y: 0, Width: 40, Height: 50}},
+	}
+	for _, tt := range tests {
+		if got := tt.shape.GetBounds(); !sameBounds(got, tt.want) {
+			t.Errorf("%s: GetBounds() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestContains(t *testing.T) {
+	line := newTestLine(Point{X: 0, Y: 0}, Point{X: 100, Y: 0})
+	outlined := newTestRect(testStyle, 0, 0, 100, 50)
+	filled := newTestRect(Style{StrokeStyle: "#000000", FillStyle: "#ff0000", LineWidth: 1}, 0, 0, 100, 50)
+
+	tests := []struct {
+		name  string
+		shape Shape
+		p     Point
+		want  bool
+	}{
+		{"line: on", line, Point{X: 50, Y: 0}, true},
+		{"line: within tolerance", line, Point{X: 50, Y: 2}, true},
+		{"line: far", line, Point{X: 50, Y: 20}, false},
+		{"line: past end", line, Point{X: 110, Y: 0}, false},
+		{"outlined rect: edge", outlined, Point{X: 0, Y: 25}, true},
+		{"outlined rect: inside", outlined, Point{X: 50, Y: 25}, false},
+		{"filled rect: inside", filled, Point{X: 50, Y: 25}, true},
+		{"filled rect: outside", filled, Point{X: 150, Y: 25}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.shape.Contains(tt.p, 1); got != tt.want {
+			t.Errorf("%s: Contains(%v) = %v, want %v", tt.name, tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestContainsFollowsViewScale(t *testing.T) {
+	line := newTestLine(Point{X: 0, Y: 0}, Point{X: 100, Y: 0})
+	p := Point{X: 50, Y: 2}
+
+	if !line.Contains(p, 1) {
+		t.Error("point 2px away is not hit at 100%")
+	}
+	// 放大後同樣的畫布距離在螢幕上更遠
+	if line.Contains(p, 4) {
+		t.Error("point 8 screen px away is hit at 400%")
+	}
+}
+
+func TestScale(t *testing.T) {
+	line := newTestLine(Point{X: 10, Y: 10}, Point{X: 20, Y: 30})
+	line.Scale(2, 3, Point{X: 10, Y: 10})
+	if want := []Point{{X: 10, Y: 10}, {X: 30, Y: 70}}; !reflect.DeepEqual(line.Points, want) {
+		t.Errorf("line points = %v, want %v", line.Points, want)
+	}
+
+	rc := newTestRect(testStyle, 10, 10, 20, 10)
+	rc.Scale(0.5, 2, Point{})
+	if want := (Bounds{X: 5, Y: 20, Width: 10, Height: 20}); !sameBounds(rc.GetBounds(), want) {
+		t.Errorf("rect bounds = %v, want %v", rc.GetBounds(), want)
+	}
+
+	// 以任何中心縮放 1 倍都不改變形狀
+	rc.Scale(1, 1, Point{X: 123, Y: -45})
+	if want := (Bounds{X: 5, Y: 20, Width: 10, Height: 20}); !sameBounds(rc.GetBounds(), want) {
+		t.Errorf("rect bounds after identity scale = %v, want %v", rc.GetBounds(), want)
+	}
+}
+
+func TestDrawRecorder(t *testing.T) {
+	line := newTestLine(Point{X: 0, Y: 0}, Point{X: 10, Y: 0}, Point{X: 10, Y: 10})
+	r := render.NewRecorder()
+	line.Draw(r)
+
+	want := []render.Call{
+		{Method: "save"},
+		{Method: "strokeStyle", Args: []interface{}{"#000000"}},
+		{Method: "lineWidth", Args: []interface{}{2.0}},
+		{Method: "beginPath"},
+		{Method: "moveTo", Args: []interface{}{0.0, 0.0}},
+		{Method: "lineTo", Args: []interface{}{10.0, 0.0}},
+		{Method: "lineTo", Args: []interface{}{10.0, 10.0}},
+		{Method: "stroke"},
+		{Method: "restore"},
+	}
+	if !reflect.DeepEqual(normalizeCalls(r.Calls), want) {
+		t.Errorf("Draw calls = %v, want %v", r.Calls, want)
+	}
+
+	// 旋轉的形狀以自身中心旋轉畫布，繪製後還原
+	rc := newTestRect(testStyle, 0, 0, 20, 10)
+	rc.Rotate(math.Pi/2, Point{X: 10, Y: 5})
+	r.Reset()
+	rc.Draw(r)
+	methods := r.Methods()
+	if len(methods) < 4 || !reflect.DeepEqual(methods[:4], []string{"save", "translate", "rotate", "translate"}) {
+		t.Errorf("rotated rect starts with %v", methods)
+	}
+	if methods[len(methods)-1] != "restore" {
+		t.Errorf("rotated rect ends with %q, want restore", methods[len(methods)-1])
+	}
+
+	// 橢圓設定的樣式不影響之後繪製的形狀
+	e := NewEllipse(Style{StrokeStyle: "#000000", FillStyle: "#ff0000", LineWidth: 1})
+	e.SetCorners(Point{}, Point{X: 20, Y: 10})
+	r.Reset()
+	e.Draw(r)
+	methods = r.Methods()
+	if methods[0] != "save" || methods[len(methods)-1] != "restore" {
+		t.Errorf("ellipse draw calls = %v, want save ... restore", methods)
+	}
+}
+
+// normalizeCalls 將沒有參數的呼叫統一為 nil，方便比較
+func normalizeCalls(calls []render.Call) []render.Call {
+	out := make([]render.Call, len(calls))
+	for i, c := range calls {
+		out[i] = c
+		if len(c.Args) == 0 {
+			out[i].Args = nil
+		}
+	}
+	return out
+}
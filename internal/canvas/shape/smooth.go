@@ -0,0 +1,228 @@
+package shape
+
+import (
+	"fmt"
+	"math"
+
+	"canvas-demo/internal/canvas/render"
+)
+
+// Smoothing 定義手繪線段的平滑方式
+type Smoothing string
+
+const (
+	SmoothNone       Smoothing = ""            // 以直線段連接各點
+	SmoothCatmullRom Smoothing = "catmull-rom" // 通過每個點的 Catmull-Rom 曲線
+	SmoothQuadratic  Smoothing = "quadratic"   // 以各點為控制點、經過相鄰點中點的二次曲線
+)
+
+// ParseSmoothing 解析平滑方式的名稱，"none" 與空字串都表示不平滑
+func ParseSmoothing(name string) (Smoothing, error) {
+	switch s := Smoothing(name); s {
+	case SmoothNone, SmoothCatmullRom, SmoothQuadratic:
+		return s, nil
+	case "none":
+		return SmoothNone, nil
+	default:
+		return SmoothNone, fmt.Errorf("unknown smoothing %q", name)
+	}
+}
+
+// SegmentKind 表示路徑片段的種類
+type SegmentKind int
+
+const (
+	SegmentLine      SegmentKind = iota // 直線，只使用 End
+	SegmentQuadratic                    // 二次貝茲曲線，使用 C1 與 End
+	SegmentCubic                        // 三次貝茲曲線，使用 C1、C2 與 End
+)
+
+// Segment 是從上一個片段的終點開始的一段路徑
+type Segment struct {
+	Kind   SegmentKind
+	C1, C2 Point
+	End    Point
+}
+
+// flattenTolerance 曲線轉成折線時每段的大約長度
+const flattenTolerance = 4.0
+
+// Simplify 以 Ramer–Douglas–Peucker 演算法移除與折線距離不超過 tolerance 的點，
+// 保留頭尾兩點與對應的筆壓。tolerance 小於等於 0 時不做任何事
+func (l *Line) Simplify(tolerance float64) {
+	if tolerance <= 0 || len(l.Points) < 3 {
+		return
+	}
+
+	keep := make([]bool, len(l.Points))
+	keep[0], keep[len(l.Points)-1] = true, true
+	simplifyRange(l.Points, 0, len(l.Points)-1, tolerance*tolerance, keep)
+
+	pressure := l.HasPressure()
+	points := make([]Point, 0, len(l.Points))
+	var pressures []float64
+	for i, p := range l.Points {
+		if !keep[i] {
+			continue
+		}
+		points = append(points, p)
+		if pressure {
+			pressures = append(pressures, l.Pressures[i])
+		}
+	}
+	l.Points, l.Pressures = points, pressures
+}
+
+// simplifyRange 標記 first 到 last 之間需要保留的點。
+// pointToLineDistance 回傳距離的平方，所以與 tolerance 的平方比較
+func simplifyRange(points []Point, first, last int, tolerance2 float64, keep []bool) {
+	for last-first > 1 {
+		index, farthest := -1, tolerance2
+		for i := first + 1; i < last; i++ {
+			if d := pointToLineDistance(points[i], points[first], points[last]); d > farthest {
+				index, farthest = i, d
+			}
+		}
+		if index < 0 {
+			return
+		}
+		keep[index] = true
+		simplifyRange(points, first, index, tolerance2, keep)
+		first = index
+	}
+}
+
+// Segments 回傳線段從第一個點開始的路徑片段（未旋轉的座標）
+func (l *Line) Segments() []Segment {
+	pts := l.Points
+	if len(pts) < 2 {
+		return nil
+	}
+
+	switch l.Smoothing {
+	case SmoothCatmullRom:
+		// 轉成等價的三次貝茲曲線，端點以自身作為相鄰點
+		segs := make([]Segment, 0, len(pts)-1)
+		for i := 0; i < len(pts)-1; i++ {
+			p0, p1, p2, p3 := pts[max(i-1, 0)], pts[i], pts[i+1], pts[min(i+2, len(pts)-1)]
+			segs = append(segs, Segment{
+				Kind: SegmentCubic,
+				C1:   Point{X: p1.X + (p2.X-p0.X)/6, Y: p1.Y + (p2.Y-p0.Y)/6},
+				C2:   Point{X: p2.X - (p3.X-p1.X)/6, Y: p2.Y - (p3.Y-p1.Y)/6},
+				End:  p2,
+			})
+		}
+		return segs
+	case SmoothQuadratic:
+		if len(pts) > 2 {
+			// 以中間的點為控制點，經過相鄰兩點的中點，最後以直線接到終點
+			segs := make([]Segment, 0, len(pts)-1)
+			for i := 1; i < len(pts)-1; i++ {
+				segs = append(segs, Segment{Kind: SegmentQuadratic, C1: pts[i], End: lerp(pts[i], pts[i+1], 0.5)})
+			}
+			return append(segs, Segment{Kind: SegmentLine, End: pts[len(pts)-1]})
+		}
+	}
+
+	segs := make([]Segment, 0, len(pts)-1)
+	for _, p := range pts[1:] {
+		segs = append(segs, Segment{Kind: SegmentLine, End: p})
+	}
+	return segs
+}
+
+// tracePath 將線段的路徑加入目前的路徑中
+func (l *Line) tracePath(r render.Renderer) {
+	r.MoveTo(l.Points[0].X, l.Points[0].Y)
+	for _, s := range l.Segments() {
+		switch s.Kind {
+		case SegmentQuadratic:
+			r.QuadraticCurveTo(s.C1.X, s.C1.Y, s.End.X, s.End.Y)
+		case SegmentCubic:
+			r.BezierCurveTo(s.C1.X, s.C1.Y, s.C2.X, s.C2.Y, s.End.X, s.End.Y)
+		default:
+			r.LineTo(s.End.X, s.End.Y)
+		}
+	}
+}
+
+// path 回傳實際繪製的路徑（未旋轉的座標），曲線會被切成折線，
+// 供選取、邊界與橡皮擦使用
+func (l *Line) path() []Point {
+	points, _ := l.flatten()
+	return points
+}
+
+// flatten 將路徑切成折線，並回傳每個點在 Points 中對應的位置（可以有小數），
+// 用來內插每個點的筆壓
+func (l *Line) flatten() (points []Point, at []float64) {
+	n := len(l.Points)
+	if l.Smoothing == SmoothNone || n < 3 {
+		at = make([]float64, n)
+		for i := range at {
+			at[i] = float64(i)
+		}
+		return l.Points, at
+	}
+
+	points = []Point{l.Points[0]}
+	at = []float64{0}
+	start, from := l.Points[0], 0.0
+	for k, s := range l.Segments() {
+		// 片段終點對應的位置：Catmull-Rom 經過每個點，二次曲線經過相鄰兩點的中點
+		to := float64(k + 1)
+		if l.Smoothing == SmoothQuadratic {
+			to = math.Min(float64(k)+1.5, float64(n-1))
+		}
+
+		steps := 1
+		switch s.Kind {
+		case SegmentQuadratic:
+			steps = flattenSteps(distanceBetween(start, s.C1) + distanceBetween(s.C1, s.End))
+		case SegmentCubic:
+			steps = flattenSteps(distanceBetween(start, s.C1) + distanceBetween(s.C1, s.C2) + distanceBetween(s.C2, s.End))
+		}
+		for i := 1; i <= steps; i++ {
+			t := float64(i) / float64(steps)
+			switch s.Kind {
+			case SegmentQuadratic:
+				points = append(points, quadraticPoint(start, s.C1, s.End, t))
+			case SegmentCubic:
+				points = append(points, cubicPoint(start, s.C1, s.C2, s.End, t))
+			default:
+				points = append(points, lerp(start, s.End, t))
+			}
+			at = append(at, from+(to-from)*t)
+		}
+		start, from = s.End, to
+	}
+	return points, at
+}
+
+// flattenSteps 依控制多邊形的長度決定曲線要切成幾段
+func flattenSteps(length float64) int {
+	return max(1, min(32, int(math.Ceil(length/flattenTolerance))))
+}
+
+// quadraticPoint 計算二次貝茲曲線在參數 t 的點
+func quadraticPoint(p0, c, p1 Point, t float64) Point {
+	u := 1 - t
+	return Point{
+		X: u*u*p0.X + 2*u*t*c.X + t*t*p1.X,
+		Y: u*u*p0.Y + 2*u*t*c.Y + t*t*p1.Y,
+	}
+}
+
+// cubicPoint 計算三次貝茲曲線在參數 t 的點
+func cubicPoint(p0, c1, c2, p1 Point, t float64) Point {
+	u := 1 - t
+	return Point{
+		X: u*u*u*p0.X + 3*u*u*t*c1.X + 3*u*t*t*c2.X + t*t*t*p1.X,
+		Y: u*u*u*p0.Y + 3*u*u*t*c1.Y + 3*u*t*t*c2.Y + t*t*t*p1.Y,
+	}
+}
+
+// distanceBetween 計算兩點之間的距離（distance 回傳的是距離的平方）
+func distanceBetween(a, b Point) float64 {
+	return math.Hypot(b.X-a.X, b.Y-a.Y)
+}
@@ -0,0 +1,152 @@
+package shape
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSimplify(t *testing.T) {
+	tests := []struct {
+		name      string
+		points    []Point
+		tolerance float64
+		want      []Point
+	}{
+		{"collinear", []Point{{X: 0}, {X: 1}, {X: 2}, {X: 3}}, 0.1, []Point{{X: 0}, {X: 3}}},
+		{"within tolerance", []Point{{X: 0}, {X: 5, Y: 0.5}, {X: 10}}, 1, []Point{{X: 0}, {X: 10}}},
+		{"keeps corner", []Point{{X: 0}, {X: 5, Y: 0.1}, {X: 10}, {X: 10, Y: 5}, {X: 10, Y: 10}}, 1, []Point{{X: 0}, {X: 10}, {X: 10, Y: 10}}},
+		{"zero tolerance", []Point{{X: 0}, {X: 1}, {X: 2}}, 0, []Point{{X: 0}, {X: 1}, {X: 2}}},
+		{"negative tolerance", []Point{{X: 0}, {X: 1}, {X: 2}}, -1, []Point{{X: 0}, {X: 1}, {X: 2}}},
+		{"two points", []Point{{X: 0}, {X: 1}}, 10, []Point{{X: 0}, {X: 1}}},
+		// 頭尾幾乎重合的筆畫仍保留每個轉角
+		{"closed", []Point{{X: 0}, {X: 10}, {X: 10.2, Y: 5}, {X: 10, Y: 10}, {X: 0.1}}, 1, []Point{{X: 0}, {X: 10}, {X: 10, Y: 10}, {X: 0.1}}},
+	}
+	for _, tt := range tests {
+		l := newTestLine(tt.points...)
+		l.Simplify(tt.tolerance)
+		if !reflect.DeepEqual(l.Points, tt.want) {
+			t.Errorf("%s: Simplify(%g) = %v, want %v", tt.name, tt.tolerance, l.Points, tt.want)
+		}
+	}
+}
+
+func TestSimplifyKeepsEndpoints(t *testing.T) {
+	l := NewLine(testStyle)
+	for x := 0.0; x <= 100; x++ {
+		l.AddPoint(Point{X: x, Y: math.Sin(x / 10)})
+	}
+	first, last := l.Points[0], l.Points[len(l.Points)-1]
+	l.Simplify(100)
+	if want := []Point{first, last}; !reflect.DeepEqual(l.Points, want) {
+		t.Errorf("Simplify = %v, want %v", l.Points, want)
+	}
+}
+
+func TestSimplifyPressures(t *testing.T) {
+	l := NewLine(testStyle)
+	l.Pen = DefaultPenStyle
+	// 每個點的筆壓等於 X / 10，方便檢查對應
+	for _, p := range []Point{{X: 0}, {X: 1}, {X: 2}, {X: 5, Y: 5}, {X: 8}, {X: 9}, {X: 10}} {
+		l.AddPressurePoint(p, p.X/10)
+	}
+	l.Simplify(0.5)
+	if want := []Point{{X: 0}, {X: 2}, {X: 5, Y: 5}, {X: 8}, {X: 10}}; !reflect.DeepEqual(l.Points, want) {
+		t.Fatalf("Points = %v, want %v", l.Points, want)
+	}
+	if len(l.Pressures) != len(l.Points) {
+		t.Fatalf("%d pressures for %d points", len(l.Pressures), len(l.Points))
+	}
+	for i, p := range l.Points {
+		if l.Pressures[i] != p.X/10 {
+			t.Errorf("Pressures[%d] = %g, want %g", i, l.Pressures[i], p.X/10)
+		}
+	}
+}
+
+func TestSegments(t *testing.T) {
+	points := []Point{{X: 0, Y: 0}, {X: 6, Y: 6}, {X: 12, Y: 0}}
+	tests := []struct {
+		smoothing Smoothing
+		want      []Segment
+	}{
+		{SmoothNone, []Segment{
+			{Kind: SegmentLine, End: Point{X: 6, Y: 6}},
+			{Kind: SegmentLine, End: Point{X: 12, Y: 0}},
+		}},
+		{SmoothCatmullRom, []Segment{
+			{Kind: SegmentCubic, C1: Point{X: 1, Y: 1}, C2: Point{X: 4, Y: 6}, End: Point{X: 6, Y: 6}},
+			{Kind: SegmentCubic, C1: Point{X: 8, Y: 6}, C2: Point{X: 11, Y: 1}, End: Point{X: 12, Y: 0}},
+		}},
+		{SmoothQuadratic, []Segment{
+			{Kind: SegmentQuadratic, C1: Point{X: 6, Y: 6}, End: Point{X: 9, Y: 3}},
+			{Kind: SegmentLine, End: Point{X: 12, Y: 0}},
+		}},
+	}
+	for _, tt := range tests {
+		l := newTestLine(points...)
+		l.Smoothing = tt.smoothing
+		if got := l.Segments(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: Segments() = %+v, want %+v", tt.smoothing, got, tt.want)
+		}
+	}
+
+	if got := newTestLine(Point{}).Segments(); got != nil {
+		t.Errorf("single point Segments() = %v, want nil", got)
+	}
+}
+
+// 平滑曲線的選取與邊界依照實際繪製的曲線，而不是各點連成的折線
+func TestSmoothedHitTestAndBounds(t *testing.T) {
+	points := []Point{{X: 0, Y: 0}, {X: 50, Y: 50}, {X: 100, Y: 0}}
+	tests := []struct {
+		smoothing Smoothing
+		maxY      float64
+		onCurve   Point // 曲線上但離折線較遠
+		offCurve  Point // 折線上但離曲線較遠
+	}{
+		{SmoothNone, 50, Point{X: 25, Y: 25}, Point{X: 21.875, Y: 28.125}},
+		// 第一段曲線在 t = 0.5 的點；二次曲線經過中點 (75, 25)，最高只到 100/3
+		{SmoothQuadratic, 100.0 / 3, quadraticPoint(points[0], points[1], Point{X: 75, Y: 25}, 0.5), Point{X: 40, Y: 40}},
+		{SmoothCatmullRom, 50, cubicPoint(points[0], Point{X: 50.0 / 6, Y: 50.0 / 6}, Point{X: 100.0 / 3, Y: 50}, points[1], 0.5), Point{X: 25, Y: 25}},
+	}
+	for _, tt := range tests {
+		l := newTestLine(points...)
+		l.Smoothing = tt.smoothing
+
+		b := l.GetBounds()
+		if b.X != 0 || b.Y != 0 || b.Width != 100 || math.Abs(b.Height-tt.maxY) > 0.5 {
+			t.Errorf("%q: GetBounds() = %+v, want height %g", tt.smoothing, b, tt.maxY)
+		}
+		if !l.Contains(tt.onCurve, 1) {
+			t.Errorf("%q: Contains(%v) on the curve = false", tt.smoothing, tt.onCurve)
+		}
+		if l.Contains(tt.offCurve, 1) {
+			t.Errorf("%q: Contains(%v) off the curve = true", tt.smoothing, tt.offCurve)
+		}
+	}
+
+	// 旋轉後的邊界也依照曲線計算
+	l := newTestLine(points...)
+	l.Smoothing = SmoothQuadratic
+	l.Rotate(math.Pi/2, Point{X: 50, Y: 50.0 / 3})
+	if b := l.GetBounds(); math.Abs(b.Width-100.0/3) > 0.5 || math.Abs(b.Height-100) > 1e-9 {
+		t.Errorf("rotated GetBounds() = %+v, want about 33x100", b)
+	}
+}
+
+func TestParseSmoothing(t *testing.T) {
+	for name, want := range map[string]Smoothing{
+		"":            SmoothNone,
+		"none":        SmoothNone,
+		"catmull-rom": SmoothCatmullRom,
+		"quadratic":   SmoothQuadratic,
+	} {
+		if got, err := ParseSmoothing(name); err != nil || got != want {
+			t.Errorf("ParseSmoothing(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseSmoothing("bezier"); err == nil {
+		t.Error("ParseSmoothing(bezier) succeeded")
+	}
+}
@@ -1,20 +1,19 @@
-//go:build js && wasm
-
 package shape
 
 import (
 	"fmt"
-	"syscall/js"
+
+	"canvas-demo/internal/canvas/render"
 )
 
 // Text 表示文字物件
 type Text struct {
-	Content    string
-	Position   Point
-	Style      TextStyle
-	isSelected bool
-	isEditing  bool     // 是否正在編輯
-	inputElem  js.Value // HTML input 元素
+	Content   string
+	Position  Point
+	Style     TextStyle
+	Rotation  float64   // 以外框中心旋轉的角度（弧度）
+	isEditing bool      // 是否正在編輯
+	input     textInput // 編輯用的輸入框（延遲建立）
 }
 
 // TextStyle 定義文字的樣式
@@ -24,83 +23,48 @@ type TextStyle struct {
 	Size      float64 // 字體大小（像素）
 }
 
+// textInput 抽象文字編輯時使用的輸入元件
+type textInput interface {
+	show(left, top float64, font, value string, angle, scale float64, pivot Point)
+	hide()
+	remove()
+}
+
 // NewText 創建新的文字物件
 func NewText(position Point, textStyle TextStyle) *Text {
-	// 創建一個輸入框元素
-	doc := js.Global().Get("document")
-	input := doc.Call("createElement", "input")
-	input.Set("type", "text")
-	input.Set("value", "新文字")
-
-	// 設置樣式
-	inputStyle := input.Get("style")
-	inputStyle.Set("position", "fixed") // 改用 fixed 定位
-	inputStyle.Set("font", textStyle.Font)
-	inputStyle.Set("color", textStyle.FillStyle)
-	inputStyle.Set("border", "1px solid #ccc") // 添加邊框以便於識別
-	inputStyle.Set("padding", "2px 4px")       // 添加內邊距
-	inputStyle.Set("outline", "none")
-	inputStyle.Set("background", "white") // 設置背景色
-	inputStyle.Set("display", "none")
-	inputStyle.Set("z-index", "1000")   // 確保在畫布上層
-	inputStyle.Set("min-width", "50px") // 最小寬度
-	inputStyle.Set("cursor", "text")    // 文字游標
-
-	// 將輸入框添加到文檔中
-	doc.Get("body").Call("appendChild", input)
-
-	text := &Text{
-		Content:   "新文字",
-		Position:  position,
-		Style:     textStyle,
-		inputElem: input,
+	return &Text{
+		Content:  "新文字",
+		Position: position,
+		Style:    textStyle,
 	}
+}
 
-	// 添加事件監聽器
-	input.Call("addEventListener", "mousedown", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		args[0].Call("stopPropagation") // 阻止冒泡到 Canvas
-		return nil
-	}))
-
-	input.Call("addEventListener", "input", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		text.Content = input.Get("value").String()
-		return nil
-	}))
-
-	// 阻止 Delete 和 Backspace 鍵冒泡到 document
-	input.Call("addEventListener", "keydown", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		event := args[0]
-		key := event.Get("key").String()
-		if key == "Delete" || key == "Backspace" {
-			event.Call("stopPropagation")
-		}
-		return nil
-	}))
-
-	return text
+// Clone 回傳文字的複製，複製品不在編輯狀態，也沒有輸入框
+func (t *Text) Clone() Shape {
+	c := NewText(t.Position, t.Style)
+	c.Content = t.Content
+	c.Rotation = t.Rotation
+	return c
 }
 
-// StartEditing 開始編輯文字
-func (t *Text) StartEditing(canvasRect js.Value) {
+// StartEditing 開始編輯文字，origin 為畫布座標原點在視窗中的位置，scale 為畫面的縮放倍率
+func (t *Text) StartEditing(origin Point, scale float64) {
 	if !t.isEditing {
 		t.isEditing = true
 
-		// 計算輸入框位置
-		left := t.Position.X + canvasRect.Get("left").Float()
-		top := t.Position.Y + canvasRect.Get("top").Float() - t.Style.Size
-
-		// 設置輸入框位置和樣式
-		style := t.inputElem.Get("style")
-		style.Set("left", fmt.Sprintf("%dpx", int(left)))
-		style.Set("top", fmt.Sprintf("%dpx", int(top)))
-		style.Set("font", t.Style.Font)
-		style.Set("display", "block")
+		// 輸入框在需要時才建立
+		if t.input == nil {
+			t.input = newTextInput(t)
+		}
 
-		t.inputElem.Set("value", t.Content)
+		// 輸入框以文字的中心（相對於未縮放的輸入框左上角）旋轉與縮放，
+		// 再讓這個中心對齊文字在視窗中的位置
+		c := t.localBounds().Center()
+		pivot := Point{X: c.X - t.Position.X, Y: c.Y - (t.Position.Y - t.Style.Size)}
+		left := c.X*scale + origin.X - pivot.X
+		top := c.Y*scale + origin.Y - pivot.Y
 
-		// 聚焦並選中全部文字
-		t.inputElem.Call("focus")
-		t.inputElem.Call("select")
+		t.input.show(left, top, t.Style.Font, t.Content, t.Rotation, scale, pivot)
 	}
 }
 
@@ -108,38 +72,42 @@ func (t *Text) StartEditing(canvasRect js.Value) {
 func (t *Text) StopEditing() {
 	if t.isEditing {
 		t.isEditing = false
-		t.inputElem.Get("style").Set("display", "none")
+		t.input.hide()
 	}
 }
 
 // Delete 刪除文字
 func (t *Text) Delete() {
 	// 移除輸入框元素
-	t.inputElem.Call("remove")
+	if t.input != nil {
+		t.isEditing = false
+		t.input.remove()
+		t.input = nil
+	}
 }
 
 // Draw 繪製文字
-func (t *Text) Draw(ctx js.Value) {
+func (t *Text) Draw(r render.Renderer) {
 	if !t.isEditing {
-		ctx.Set("font", t.Style.Font)
-		ctx.Set("fillStyle", t.Style.FillStyle)
+		beginRotation(r, t.localBounds().Center(), t.Rotation)
+		defer r.Restore()
 
-		// 繪製文字
-		ctx.Call("fillText", t.Content, t.Position.X, t.Position.Y)
-	}
+		r.SetFont(t.Style.Font)
+		r.SetFillStyle(t.Style.FillStyle)
 
-	// 如果被選中，繪製控制點
-	if t.isSelected {
-		t.DrawControls(ctx)
+		// 繪製文字
+		r.FillText(t.Content, t.Position.X, t.Position.Y)
 	}
 }
 
 // Contains 檢查點是否在文字範圍內
-func (t *Text) Contains(p Point) bool {
+func (t *Text) Contains(p Point, scale float64) bool {
 	if t.isEditing {
 		return false // 編輯時不處理選中
 	}
-	bounds := t.GetBounds()
+	bounds := t.localBounds()
+	// 轉回未旋轉的座標系再比較
+	p = rotatePoint(p, bounds.Center(), -t.Rotation)
 	return p.X >= bounds.X && p.X <= bounds.X+bounds.Width &&
 		p.Y >= bounds.Y && p.Y <= bounds.Y+bounds.Height
 }
@@ -150,8 +118,18 @@ func (t *Text) Move(dx, dy float64) {
 	t.Position.Y += dy
 }
 
-// GetBounds 獲取文字的邊界
+// GetBounds 獲取文字旋轉後的邊界
 func (t *Text) GetBounds() Bounds {
+	return rotatedBounds(t.localBounds(), t.Rotation)
+}
+
+// OrientedBounds 回傳未旋轉時的外框與旋轉角度
+func (t *Text) OrientedBounds() (Bounds, float64) {
+	return t.localBounds(), t.Rotation
+}
+
+// localBounds 獲取文字未旋轉時的邊界
+func (t *Text) localBounds() Bounds {
 	// 這裡需要使用 JavaScript 的 measureText 來獲取文字的實際尺寸
 	// 由於無法直接在這裡訪問 context，我們使用一個估算值
 	width := float64(len(t.Content)) * t.Style.Size * 0.6 // 估算寬度
@@ -167,6 +145,25 @@ func (t *Text) GetBounds() Bounds {
 
 // Scale 縮放文字
 func (t *Text) Scale(sx, sy float64, center Point) {
+	if t.Rotation != 0 {
+		// 在未旋轉的座標系中以自身中心縮放，再移到縮放後的位置
+		c := t.localBounds().Center()
+		dx, dy := scaledCenter(c, center, sx, sy)
+		t.scaleAround(sx, sy, c)
+		t.Move(dx, dy)
+		return
+	}
+	t.scaleAround(sx, sy, center)
+}
+
+// Rotate 以 pivot 為中心旋轉文字
+func (t *Text) Rotate(angle float64, pivot Point) {
+	t.Move(rotatedCenter(t.localBounds().Center(), pivot, angle))
+	t.Rotation = normalizeAngle(t.Rotation + angle)
+}
+
+// scaleAround 相對於中心點縮放位置與字體大小
+func (t *Text) scaleAround(sx, sy float64, center Point) {
 	// 相對於中心點進行縮放
 	dx := t.Position.X - center.X
 	dy := t.Position.Y - center.Y
@@ -180,72 +177,11 @@ func (t *Text) Scale(sx, sy float64, center Point) {
 }
 
 // DrawControls 繪製控制點
-func (t *Text) DrawControls(ctx js.Value) {
-	bounds := t.GetBounds()
-
-	// 保存當前繪圖狀態
-	ctx.Call("save")
-
-	// 設置控制點樣式
-	ctx.Set("fillStyle", "#ffffff")
-	ctx.Set("strokeStyle", "#000000")
-	ctx.Set("lineWidth", 1)
-
-	// 繪製邊界框
-	ctx.Call("beginPath")
-	ctx.Call("rect", bounds.X, bounds.Y, bounds.Width, bounds.Height)
-	ctx.Call("stroke")
-
-	const controlSize = 5.0 // 控制點大小
-
-	// 繪製控制點
-	controlPoints := []struct {
-		x, y float64
-	}{
-		{bounds.X, bounds.Y},                                // TopLeft
-		{bounds.X + bounds.Width, bounds.Y},                 // TopRight
-		{bounds.X, bounds.Y + bounds.Height},                // BottomLeft
-		{bounds.X + bounds.Width, bounds.Y + bounds.Height}, // BottomRight
-	}
-
-	for _, cp := range controlPoints {
-		ctx.Call("beginPath")
-		ctx.Call("arc", cp.x, cp.y, controlSize, 0, 2*3.14159, false)
-		ctx.Call("fill")
-		ctx.Call("stroke")
-	}
-
-	// 恢復繪圖狀態
-	ctx.Call("restore")
+func (t *Text) DrawControls(r render.Renderer, scale float64) {
+	DrawBoundsControls(r, t.localBounds(), t.Rotation, scale)
 }
 
 // HitControl 檢查是否點擊到控制點
-func (t *Text) HitControl(p Point) ControlPoint {
-	bounds := t.GetBounds()
-	const controlSize = 5.0         // 視覺上的控制點大小
-	const hitArea = controlSize * 4 // 增加點選範圍到視覺大小的4倍
-
-	// 檢查各個控制點
-	controlPoints := []struct {
-		point ControlPoint
-		x, y  float64
-	}{
-		{TopLeft, bounds.X, bounds.Y},
-		{TopRight, bounds.X + bounds.Width, bounds.Y},
-		{BottomLeft, bounds.X, bounds.Y + bounds.Height},
-		{BottomRight, bounds.X + bounds.Width, bounds.Y + bounds.Height},
-	}
-
-	for _, cp := range controlPoints {
-		if distance(p, Point{X: cp.x, Y: cp.y}) <= hitArea {
-			return cp.point
-		}
-	}
-
-	return None
-}
-
-// SetSelected 設置選中狀態
-func (t *Text) SetSelected(selected bool) {
-	t.isSelected = selected
+func (t *Text) HitControl(p Point, scale float64) ControlPoint {
+	return HitBoundsControls(p, t.localBounds(), t.Rotation, scale)
 }
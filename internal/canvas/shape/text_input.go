@@ -0,0 +1,100 @@
+//go:build js && wasm
+
+package shape
+
+import (
+	"fmt"
+	"syscall/js"
+)
+
+// domInput 以 HTML input 元素實作 textInput
+type domInput struct {
+	elem  js.Value
+	funcs []js.Func
+}
+
+// newTextInput 為文字物件創建 HTML 輸入框
+func newTextInput(t *Text) textInput {
+	// 創建一個輸入框元素
+	doc := js.Global().Get("document")
+	input := doc.Call("createElement", "input")
+	input.Set("type", "text")
+	input.Set("value", t.Content)
+
+	// 設置樣式
+	inputStyle := input.Get("style")
+	inputStyle.Set("position", "fixed") // 改用 fixed 定位
+	inputStyle.Set("font", t.Style.Font)
+	inputStyle.Set("color", t.Style.FillStyle)
+	inputStyle.Set("border", "1px solid #ccc") // 添加邊框以便於識別
+	inputStyle.Set("padding", "2px 4px")       // 添加內邊距
+	inputStyle.Set("outline", "none")
+	inputStyle.Set("background", "white") // 設置背景色
+	inputStyle.Set("display", "none")
+	inputStyle.Set("z-index", "1000")   // 確保在畫布上層
+	inputStyle.Set("min-width", "50px") // 最小寬度
+	inputStyle.Set("cursor", "text")    // 文字游標
+
+	// 將輸入框添加到文檔中
+	doc.Get("body").Call("appendChild", input)
+
+	d := &domInput{elem: input}
+
+	// 添加事件監聽器
+	d.listen("mousedown", func(event js.Value) {
+		event.Call("stopPropagation") // 阻止冒泡到 Canvas
+	})
+
+	d.listen("input", func(event js.Value) {
+		t.Content = input.Get("value").String()
+	})
+
+	// 阻止 Delete 和 Backspace 鍵冒泡到 document
+	d.listen("keydown", func(event js.Value) {
+		key := event.Get("key").String()
+		if key == "Delete" || key == "Backspace" {
+			event.Call("stopPropagation")
+		}
+	})
+
+	return d
+}
+
+// listen 註冊事件監聽器並保留 js.Func 以便之後釋放
+func (d *domInput) listen(event string, handler func(event js.Value)) {
+	fn := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		handler(args[0])
+		return nil
+	})
+	d.funcs = append(d.funcs, fn)
+	d.elem.Call("addEventListener", event, fn)
+}
+
+func (d *domInput) show(left, top float64, font, value string, angle, scale float64, pivot Point) {
+	// 設置輸入框位置和樣式
+	style := d.elem.Get("style")
+	style.Set("left", fmt.Sprintf("%dpx", int(left)))
+	style.Set("top", fmt.Sprintf("%dpx", int(top)))
+	style.Set("font", font)
+	style.Set("transform-origin", fmt.Sprintf("%.1fpx %.1fpx", pivot.X, pivot.Y))
+	style.Set("transform", fmt.Sprintf("rotate(%grad) scale(%g)", angle, scale))
+	style.Set("display", "block")
+
+	d.elem.Set("value", value)
+
+	// 聚焦並選中全部文字
+	d.elem.Call("focus")
+	d.elem.Call("select")
+}
+
+func (d *domInput) hide() {
+	d.elem.Get("style").Set("display", "none")
+}
+
+func (d *domInput) remove() {
+	d.elem.Call("remove")
+	for _, fn := range d.funcs {
+		fn.Release()
+	}
+	d.funcs = nil
+}
@@ -0,0 +1,15 @@
+//go:build !(js && wasm)
+
+package shape
+
+// nopInput 在非瀏覽器環境中不做任何事
+type nopInput struct{}
+
+// newTextInput 在非瀏覽器環境中回傳空實作
+func newTextInput(t *Text) textInput {
+	return nopInput{}
+}
+
+func (nopInput) show(left, top float64, font, value string, angle, scale float64, pivot Point) {}
+func (nopInput) hide()                                                                         {}
+func (nopInput) remove()                                                                       {}
@@ -0,0 +1,15 @@
+package shape
+
+// 控制點大小與點選容差以螢幕像素定義。點選與繪製控制點的函式接收畫面的縮放倍率 scale，
+// 換算成畫布座標時除以這個倍率，縮放畫面時才能維持相同的螢幕大小
+
+// screenLength 將螢幕上的長度換算成畫布座標
+func screenLength(length, scale float64) float64 {
+	return length / scale
+}
+
+// screenTolerance 將螢幕上的點選容差換算成畫布座標。
+// distance 與 pointToLineDistance 回傳距離的平方，所以容差要除以倍率的平方
+func screenTolerance(tolerance, scale float64) float64 {
+	return tolerance / (scale * scale)
+}
@@ -0,0 +1,31 @@
+//go:build js && wasm
+
+package canvas
+
+import (
+	"canvas-demo/internal/canvas/grid"
+	"canvas-demo/internal/canvas/shape"
+)
+
+// Grid 回傳目前的格線設定
+func (cm *CanvasManager) Grid() grid.Grid {
+	return cm.grid
+}
+
+// SetGrid 設置格線的間隔、細分、顏色、是否顯示與是否對齊，設定不正確時保持原狀
+func (cm *CanvasManager) SetGrid(g grid.Grid) error {
+	if err := g.Validate(); err != nil {
+		return err
+	}
+	cm.grid = g
+	cm.redraw()
+	return nil
+}
+
+// snapPoint 在開啟對齊格線時回傳最接近 p 的格點，按住 Alt 時暫時不對齊
+func (cm *CanvasManager) snapPoint(p shape.Point) shape.Point {
+	if !cm.grid.Snap || cm.modifiers.Alt {
+		return p
+	}
+	return cm.grid.SnapPoint(p)
+}
@@ -0,0 +1,246 @@
+// Package spatial 提供以邊界框索引形狀的四元樹，用於點選、框選與畫面裁剪時
+// 快速找出可能相關的形狀，不需要逐一檢查畫布上的每個形狀。
+package spatial
+
+import (
+	"math"
+
+	"canvas-demo/internal/canvas/shape"
+)
+
+const (
+	maxEntries    = 8      // 節點中的形狀超過這個數量時分裂
+	minNodeSize   = 16.0   // 小於這個大小的節點不再分裂
+	initialSize   = 1024.0 // 第一個節點的大小，範圍不夠時往外加倍
+	maxCoordinate = 1e15   // 邊界超出這個範圍的形狀不加入索引
+	maxGrowth     = 64     // 根節點最多加倍的次數，足以涵蓋 maxCoordinate
+)
+
+// Index 是形狀的空間索引。形狀的邊界在加入時記錄，形狀移動或改變大小後需要呼叫 Update
+type Index struct {
+	root   *node
+	bounds map[shape.Shape]shape.Bounds // 每個形狀在索引中的邊界
+}
+
+// entry 是索引中的一個形狀
+type entry struct {
+	shape  shape.Shape
+	bounds shape.Bounds
+}
+
+// node 是四元樹的節點，放不進任何一個子節點的形狀留在節點本身
+type node struct {
+	bounds   shape.Bounds
+	entries  []entry
+	children *[4]*node
+}
+
+// New 創建空的索引
+func New() *Index {
+	return &Index{bounds: make(map[shape.Shape]shape.Bounds)}
+}
+
+// Len 回傳索引中的形狀數量
+func (ix *Index) Len() int {
+	return len(ix.bounds)
+}
+
+// Insert 將形狀加入索引，已在索引中時更新它的邊界。
+// 邊界不是有限數值或超出 maxCoordinate 的形狀無法定位，不會加入索引
+func (ix *Index) Insert(s shape.Shape) {
+	if _, ok := ix.bounds[s]; ok {
+		ix.Update(s)
+		return
+	}
+	e := entry{shape: s, bounds: s.GetBounds()}
+	if !validBounds(e.bounds) {
+		return
+	}
+	ix.bounds[s] = e.bounds
+
+	if ix.root == nil {
+		c := e.bounds.Center()
+		ix.root = &node{bounds: shape.Bounds{
+			X:      c.X - initialSize/2,
+			Y:      c.Y - initialSize/2,
+			Width:  initialSize,
+			Height: initialSize,
+		}}
+	}
+	for i := 0; i < maxGrowth && !ix.root.bounds.Encloses(e.bounds); i++ {
+		ix.grow(e.bounds)
+	}
+	ix.root.insert(e)
+}
+
+// Remove 將形狀移出索引，不在索引中時不做任何事
+func (ix *Index) Remove(s shape.Shape) {
+	b, ok := ix.bounds[s]
+	if !ok {
+		return
+	}
+	delete(ix.bounds, s)
+	ix.root.remove(s, b)
+}
+
+// Update 重新讀取形狀的邊界，不在索引中的形狀會被忽略。
+// 新的邊界無法定位時將形狀移出索引
+func (ix *Index) Update(s shape.Shape) {
+	b, ok := ix.bounds[s]
+	if !ok || b == s.GetBounds() {
+		return
+	}
+	ix.Remove(s)
+	ix.Insert(s)
+}
+
+// Bounds 回傳形狀在索引中記錄的邊界，也就是最後一次加入或更新時的邊界
+func (ix *Index) Bounds(s shape.Shape) (shape.Bounds, bool) {
+	b, ok := ix.bounds[s]
+	return b, ok
+}
+
+// Clear 移除索引中的所有形狀
+func (ix *Index) Clear() {
+	ix.root = nil
+	ix.bounds = make(map[shape.Shape]shape.Bounds)
+}
+
+// Search 回傳邊界與 area 重疊的所有形狀，不保證順序
+func (ix *Index) Search(area shape.Bounds) []shape.Shape {
+	if ix.root == nil {
+		return nil
+	}
+	var result []shape.Shape
+	ix.root.search(area, &result)
+	return result
+}
+
+// validBounds 檢查邊界是有限數值且在 maxCoordinate 之內
+func validBounds(b shape.Bounds) bool {
+	for _, v := range []float64{b.X, b.Y, b.X + b.Width, b.Y + b.Height} {
+		if math.IsNaN(v) || math.Abs(v) > maxCoordinate {
+			return false
+		}
+	}
+	return b.Width >= 0 && b.Height >= 0
+}
+
+// Pick 回傳 shapes 中包含 p 的最上層形狀（越後面越上層），沒有時回傳 nil。
+// 只對索引中邊界與 p 周圍 margin 範圍重疊的形狀呼叫 Contains，scale 為畫面的縮放倍率
+func (ix *Index) Pick(shapes []shape.Shape, p shape.Point, margin, scale float64) shape.Shape {
+	found := ix.Search(shape.Bounds{X: p.X, Y: p.Y}.Inflate(margin))
+	if len(found) == 0 {
+		return nil
+	}
+	candidates := make(map[shape.Shape]bool, len(found))
+	for _, s := range found {
+		candidates[s] = true
+	}
+	for i := len(shapes) - 1; i >= 0; i-- {
+		if candidates[shapes[i]] && shapes[i].Contains(p, scale) {
+			return shapes[i]
+		}
+	}
+	return nil
+}
+
+// grow 將根節點往 b 的方向加倍，原本的根節點成為新根節點的一個子節點
+func (ix *Index) grow(b shape.Bounds) {
+	old := ix.root
+	size := old.bounds.Width
+	x, y := old.bounds.X, old.bounds.Y
+	quadrant := 0 // 原本的根節點在新根節點中的位置，與 quadrants 的順序相同
+	if b.X < x {
+		x -= size
+		quadrant++
+	}
+	if b.Y < y {
+		y -= size
+		quadrant += 2
+	}
+
+	root := &node{bounds: shape.Bounds{X: x, Y: y, Width: size * 2, Height: size * 2}}
+	root.children = root.quadrants()
+	root.children[quadrant] = old
+	ix.root = root
+}
+
+// quadrants 建立四個等大的空子節點
+func (n *node) quadrants() *[4]*node {
+	half := n.bounds.Width / 2
+	var children [4]*node
+	for i := range children {
+		children[i] = &node{bounds: shape.Bounds{
+			X:      n.bounds.X + half*float64(i%2),
+			Y:      n.bounds.Y + half*float64(i/2),
+			Width:  half,
+			Height: half,
+		}}
+	}
+	return &children
+}
+
+// childFor 回傳可以完全容納 b 的子節點，沒有時回傳 nil
+func (n *node) childFor(b shape.Bounds) *node {
+	if n.children == nil {
+		return nil
+	}
+	for _, c := range n.children {
+		if c.bounds.Encloses(b) {
+			return c
+		}
+	}
+	return nil
+}
+
+func (n *node) insert(e entry) {
+	if c := n.childFor(e.bounds); c != nil {
+		c.insert(e)
+		return
+	}
+	n.entries = append(n.entries, e)
+	if n.children == nil && len(n.entries) > maxEntries && n.bounds.Width > minNodeSize {
+		n.split()
+	}
+}
+
+// split 建立子節點並將放得進子節點的形狀往下移
+func (n *node) split() {
+	n.children = n.quadrants()
+	entries := n.entries
+	n.entries = nil
+	for _, e := range entries {
+		n.insert(e)
+	}
+}
+
+// remove 沿著加入時的路徑找到形狀並移除
+func (n *node) remove(s shape.Shape, b shape.Bounds) bool {
+	if c := n.childFor(b); c != nil && c.remove(s, b) {
+		return true
+	}
+	for i, e := range n.entries {
+		if e.shape == s {
+			n.entries = append(n.entries[:i], n.entries[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (n *node) search(area shape.Bounds, result *[]shape.Shape) {
+	if !n.bounds.Intersects(area) {
+		return
+	}
+	for _, e := range n.entries {
+		if e.bounds.Intersects(area) {
+			*result = append(*result, e.shape)
+		}
+	}
+	if n.children != nil {
+		for _, c := range n.children {
+			c.search(area, result)
+		}
+	}
+}
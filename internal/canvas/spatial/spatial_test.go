@@ -0,0 +1,220 @@
+package spatial
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"canvas-demo/internal/canvas/shape"
+)
+
+// newRect 建立左上角在 (x, y)、大小為 w×h 的矩形
+func newRect(x, y, w, h float64) *shape.Rect {
+	r := shape.NewRect(shape.Style{StrokeStyle: "#000000", LineWidth: 1})
+	r.SetCorners(shape.Point{X: x, Y: y}, shape.Point{X: x + w, Y: y + h})
+	return r
+}
+
+func TestInsertInvalidBounds(t *testing.T) {
+	ix := New()
+	ix.Insert(newRect(0, 0, 10, 10))
+	for _, r := range []*shape.Rect{
+		newRect(math.NaN(), 0, 10, 10),
+		newRect(0, math.Inf(1), 10, 10),
+		newRect(1e300, 0, 10, 10),
+	} {
+		ix.Insert(r)
+		if _, ok := ix.Bounds(r); ok {
+			t.Errorf("Insert(%v) added shape with invalid bounds", r.GetBounds())
+		}
+	}
+	if ix.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", ix.Len())
+	}
+}
+
+func TestUpdateToInvalidBounds(t *testing.T) {
+	ix := New()
+	r := newRect(0, 0, 10, 10)
+	ix.Insert(r)
+	r.Move(math.NaN(), 0)
+	ix.Update(r)
+	if _, ok := ix.Bounds(r); ok {
+		t.Error("shape with NaN bounds is still indexed")
+	}
+	if got := ix.Search(shape.Bounds{X: -1e6, Y: -1e6, Width: 2e6, Height: 2e6}); len(got) != 0 {
+		t.Errorf("Search() = %v, want none", got)
+	}
+}
+
+// 隨機筆畫分布的範圍、每筆的點數與查詢範圍的大小
+const (
+	worldSize    = 10000.0
+	strokePoints = 50
+	strokeStep   = 8.0
+	viewSize     = 800.0
+	benchShapes  = 5000
+)
+
+// randomStrokes 產生 n 筆隨機漫步的平滑筆畫
+func randomStrokes(rng *rand.Rand, n int) []shape.Shape {
+	shapes := make([]shape.Shape, n)
+	for i := range shapes {
+		line := shape.NewLine(shape.Style{StrokeStyle: "#000000", LineWidth: 2})
+		line.Smoothing = shape.SmoothCatmullRom
+		p := shape.Point{X: rng.Float64() * worldSize, Y: rng.Float64() * worldSize}
+		for j := 0; j < strokePoints; j++ {
+			line.AddPoint(p)
+			p.X += (rng.Float64()*2 - 1) * strokeStep
+			p.Y += (rng.Float64()*2 - 1) * strokeStep
+		}
+		shapes[i] = line
+	}
+	return shapes
+}
+
+// randomArea 回傳畫面大小的隨機範圍
+func randomArea(rng *rand.Rand) shape.Bounds {
+	return shape.Bounds{
+		X:      rng.Float64()*(worldSize+viewSize) - viewSize,
+		Y:      rng.Float64()*(worldSize+viewSize) - viewSize,
+		Width:  viewSize * rng.Float64(),
+		Height: viewSize * rng.Float64(),
+	}
+}
+
+// searchLinear 逐一檢查每個形狀的邊界是否與 area 重疊
+func searchLinear(shapes []shape.Shape, area shape.Bounds) map[shape.Shape]bool {
+	result := make(map[shape.Shape]bool)
+	for _, s := range shapes {
+		if area.Intersects(s.GetBounds()) {
+			result[s] = true
+		}
+	}
+	return result
+}
+
+// pickLinear 從最上層開始逐一檢查每個形狀
+func pickLinear(shapes []shape.Shape, p shape.Point) shape.Shape {
+	for i := len(shapes) - 1; i >= 0; i-- {
+		if shapes[i].Contains(p, 1) {
+			return shapes[i]
+		}
+	}
+	return nil
+}
+
+// checkMatchesLinear 比較索引與逐一檢查在隨機範圍與點上的結果
+func checkMatchesLinear(t *testing.T, rng *rand.Rand, ix *Index, shapes []shape.Shape) {
+	t.Helper()
+	if ix.Len() != len(shapes) {
+		t.Fatalf("Len() = %d, want %d", ix.Len(), len(shapes))
+	}
+	for i := 0; i < 50; i++ {
+		area := randomArea(rng)
+		want := searchLinear(shapes, area)
+		got := ix.Search(area)
+		if len(got) != len(want) {
+			t.Fatalf("Search(%v) found %d shapes, want %d", area, len(got), len(want))
+		}
+		for _, s := range got {
+			if !want[s] {
+				t.Fatalf("Search(%v) returned a shape outside the area", area)
+			}
+		}
+
+		line := shapes[rng.Intn(len(shapes))].(*shape.Line)
+		p := line.Points[rng.Intn(len(line.Points))]
+		if got, want := ix.Pick(shapes, p, 8, 1), pickLinear(shapes, p); got != want {
+			t.Fatalf("Pick(%v) = %p, want %p", p, got, want)
+		}
+	}
+}
+
+func TestIndexMatchesLinearScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	shapes := randomStrokes(rng, 300)
+	ix := New()
+	for _, s := range shapes {
+		ix.Insert(s)
+	}
+	checkMatchesLinear(t, rng, ix, shapes)
+
+	// 移動
+	for _, s := range shapes[:60] {
+		s.Move(rng.Float64()*2000-1000, rng.Float64()*2000-1000)
+		ix.Update(s)
+	}
+	checkMatchesLinear(t, rng, ix, shapes)
+
+	// 縮放
+	for _, s := range shapes[60:120] {
+		c := s.GetBounds().Center()
+		f := 0.2 + rng.Float64()*5
+		s.Scale(f, f, c)
+		ix.Update(s)
+	}
+	checkMatchesLinear(t, rng, ix, shapes)
+
+	// 刪除
+	for _, s := range shapes[120:180] {
+		ix.Remove(s)
+	}
+	shapes = append(shapes[:120], shapes[180:]...)
+	checkMatchesLinear(t, rng, ix, shapes)
+}
+
+// benchSetup 建立基準測試用的筆畫、索引與查詢的點和範圍，點落在筆畫上以模擬實際點選
+func benchSetup() ([]shape.Shape, *Index, []shape.Point, []shape.Bounds) {
+	rng := rand.New(rand.NewSource(1))
+	shapes := randomStrokes(rng, benchShapes)
+	ix := New()
+	for _, s := range shapes {
+		ix.Insert(s)
+	}
+	points := make([]shape.Point, 256)
+	areas := make([]shape.Bounds, 256)
+	for i := range points {
+		line := shapes[rng.Intn(len(shapes))].(*shape.Line)
+		points[i] = line.Points[rng.Intn(len(line.Points))]
+		areas[i] = shape.Bounds{
+			X:      rng.Float64() * (worldSize - viewSize),
+			Y:      rng.Float64() * (worldSize - viewSize),
+			Width:  viewSize,
+			Height: viewSize,
+		}
+	}
+	return shapes, ix, points, areas
+}
+
+func BenchmarkPickLinear(b *testing.B) {
+	shapes, _, points, _ := benchSetup()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pickLinear(shapes, points[i%len(points)])
+	}
+}
+
+func BenchmarkPickIndexed(b *testing.B) {
+	shapes, ix, points, _ := benchSetup()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ix.Pick(shapes, points[i%len(points)], 8, 1)
+	}
+}
+
+func BenchmarkSearchLinear(b *testing.B) {
+	shapes, _, _, areas := benchSetup()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		searchLinear(shapes, areas[i%len(areas)])
+	}
+}
+
+func BenchmarkSearchIndexed(b *testing.B) {
+	_, ix, _, areas := benchSetup()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ix.Search(areas[i%len(areas)])
+	}
+}
@@ -0,0 +1,45 @@
+//go:build js && wasm
+
+package canvas
+
+import (
+	"math"
+
+	"canvas-demo/internal/canvas/shape"
+)
+
+// defaultSimplifyTolerance 完成筆畫時簡化的預設容差
+const defaultSimplifyTolerance = 1.0
+
+// SetSimplifyTolerance 設置完成筆畫時移除多餘點的容差，0 表示保留所有點
+func (cm *CanvasManager) SetSimplifyTolerance(tolerance float64) {
+	cm.simplifyTolerance = math.Max(tolerance, 0)
+}
+
+// SetPenStyle 設置觸控筆筆畫的寬度曲線：最小與最大寬度、筆壓影響程度與兩端變細的長度
+func (cm *CanvasManager) SetPenStyle(style shape.PenStyle) error {
+	if err := style.Validate(); err != nil {
+		return err
+	}
+	cm.penStyle = style
+	return nil
+}
+
+// addStrokePoint 將點加入正在繪製的筆畫，筆壓筆畫同時記錄目前指標的筆壓
+func (cm *CanvasManager) addStrokePoint(p shape.Point) {
+	if cm.currentLine.HasPressure() {
+		cm.currentLine.AddPressurePoint(p, cm.pointer.Pressure)
+		return
+	}
+	cm.currentLine.AddPoint(p)
+}
+
+// SetSmoothing 設置新筆畫的平滑方式：none、catmull-rom 或 quadratic
+func (cm *CanvasManager) SetSmoothing(name string) error {
+	smoothing, err := shape.ParseSmoothing(name)
+	if err != nil {
+		return err
+	}
+	cm.smoothing = smoothing
+	return nil
+}
@@ -0,0 +1,243 @@
+// Package svg 將形狀序列化為 SVG 文件，不依賴瀏覽器環境。
+package svg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"canvas-demo/internal/canvas/layer"
+	"canvas-demo/internal/canvas/shape"
+)
+
+// Encode 將形狀寫成 SVG，view 指定輸出的可視範圍
+func Encode(w io.Writer, shapes []shape.Shape, view shape.Bounds) error {
+	return encode(w, view, func(buf *bytes.Buffer) error {
+		for i, s := range shapes {
+			if err := writeShape(buf, s); err != nil {
+				return fmt.Errorf("svg: shapes[%d]: %w", i, err)
+			}
+		}
+		return nil
+	})
+}
+
+// EncodeLayers 將圖層寫成 SVG：隱藏的圖層不輸出，半透明的圖層放在有 opacity 的 <g> 中
+func EncodeLayers(w io.Writer, layers []*layer.Layer, view shape.Bounds) error {
+	return encode(w, view, func(buf *bytes.Buffer) error {
+		for i, l := range layers {
+			if !l.Visible {
+				continue
+			}
+			if l.Opacity < 1 {
+				fmt.Fprintf(buf, `  <g opacity="%s">`+"\n", num(l.Opacity))
+			}
+			for j, s := range l.Shapes {
+				if err := writeShape(buf, s); err != nil {
+					return fmt.Errorf("svg: layers[%d]: shapes[%d]: %w", i, j, err)
+				}
+			}
+			if l.Opacity < 1 {
+				buf.WriteString("  </g>\n")
+			}
+		}
+		return nil
+	})
+}
+
+// encode 輸出 SVG 的開頭與結尾，內容由 body 寫入
+func encode(w io.Writer, view shape.Bounds, body func(buf *bytes.Buffer) error) error {
+	var buf bytes.Buffer
+
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="%s %s %s %s">`+"\n",
+		num(view.Width), num(view.Height), num(view.X), num(view.Y), num(view.Width), num(view.Height))
+
+	if err := body(&buf); err != nil {
+		return err
+	}
+
+	buf.WriteString("</svg>\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Marshal 將形狀轉為 SVG 字串
+func Marshal(shapes []shape.Shape, view shape.Bounds) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := Encode(&buf, shapes, view); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// MarshalLayers 將圖層轉為 SVG 字串
+func MarshalLayers(layers []*layer.Layer, view shape.Bounds) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := EncodeLayers(&buf, layers, view); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeShape 依形狀類型輸出對應的 SVG 元素
+func writeShape(buf *bytes.Buffer, s shape.Shape) error {
+	switch v := s.(type) {
+	case *shape.Line:
+		writeLine(buf, v)
+	case *shape.Text:
+		writeText(buf, v)
+	case *shape.Connector:
+		writeConnector(buf, v)
+	case *shape.Group:
+		return writeGroup(buf, v)
+	case *shape.Rect:
+		fmt.Fprintf(buf, `  <rect x="%s" y="%s" width="%s" height="%s"%s%s/>`+"\n",
+			num(v.X), num(v.Y), num(v.Width), num(v.Height), paint(v.Style), transform(v))
+	case *shape.Ellipse:
+		fmt.Fprintf(buf, `  <ellipse cx="%s" cy="%s" rx="%s" ry="%s"%s%s/>`+"\n",
+			num(v.X+v.Width/2), num(v.Y+v.Height/2), num(v.Width/2), num(v.Height/2), paint(v.Style), transform(v))
+	default:
+		return fmt.Errorf("unsupported shape %T", s)
+	}
+	return nil
+}
+
+// writeLine 將線段輸出為 <polyline>，平滑的線段輸出為 <path>，筆壓筆畫輸出為 <polygon>
+func writeLine(buf *bytes.Buffer, l *shape.Line) {
+	// 與 Canvas 相同，少於兩個點的線段不繪製
+	if len(l.Points) < 2 {
+		return
+	}
+
+	if l.HasPressure() {
+		// 可變寬度的筆畫輸出為填滿的外框
+		fmt.Fprintf(buf, `  <polygon points="%s" fill="%s"%s/>`+"\n",
+			points(l.Outline()), attr(l.Style.StrokeStyle), transform(l))
+		return
+	}
+
+	if l.Smoothing != shape.SmoothNone {
+		fmt.Fprintf(buf, `  <path d="%s" fill="none" stroke="%s" stroke-width="%s"%s/>`+"\n",
+			pathData(l), attr(l.Style.StrokeStyle), num(l.Style.LineWidth), transform(l))
+		return
+	}
+
+	fmt.Fprintf(buf, `  <polyline points="%s" fill="none" stroke="%s" stroke-width="%s"%s/>`+"\n",
+		points(l.Points), attr(l.Style.StrokeStyle), num(l.Style.LineWidth), transform(l))
+}
+
+// writeConnector 將扣除標記後的線段輸出為 <line>，端點標記放在同一個 <g> 中
+func writeConnector(buf *bytes.Buffer, c *shape.Connector) {
+	color := attr(c.Style.StrokeStyle)
+	fmt.Fprintf(buf, `  <g stroke="%s" stroke-width="%s" fill="%s">`+"\n", color, num(c.Style.LineWidth), color)
+	start, end := c.Shaft()
+	fmt.Fprintf(buf, `    <line x1="%s" y1="%s" x2="%s" y2="%s"/>`+"\n",
+		num(start.X), num(start.Y), num(end.X), num(end.Y))
+
+	for _, m := range c.MarkerGeometry() {
+		switch m.Kind {
+		case shape.MarkerArrow:
+			fmt.Fprintf(buf, `    <polygon points="%s" stroke="none"/>`+"\n", points(m.Points))
+		case shape.MarkerDot:
+			fmt.Fprintf(buf, `    <circle cx="%s" cy="%s" r="%s" stroke="none"/>`+"\n",
+				num(m.Points[0].X), num(m.Points[0].Y), num(m.Radius))
+		case shape.MarkerBar:
+			fmt.Fprintf(buf, `    <line x1="%s" y1="%s" x2="%s" y2="%s"/>`+"\n",
+				num(m.Points[0].X), num(m.Points[0].Y), num(m.Points[1].X), num(m.Points[1].Y))
+		}
+	}
+
+	buf.WriteString("  </g>\n")
+}
+
+// writeGroup 將群組輸出為 <g>，子形狀依序寫在其中
+func writeGroup(buf *bytes.Buffer, g *shape.Group) error {
+	buf.WriteString("  <g>\n")
+	for i, c := range g.Children {
+		if err := writeShape(buf, c); err != nil {
+			return fmt.Errorf("children[%d]: %w", i, err)
+		}
+	}
+	buf.WriteString("  </g>\n")
+	return nil
+}
+
+// writeText 將文字輸出為 <text>
+func writeText(buf *bytes.Buffer, t *shape.Text) {
+	fmt.Fprintf(buf, `  <text x="%s" y="%s" style="font: %s" fill="%s"%s>`,
+		num(t.Position.X), num(t.Position.Y), attr(t.Style.Font), attr(t.Style.FillStyle), transform(t))
+	xml.EscapeText(buf, []byte(t.Content))
+	buf.WriteString("</text>\n")
+}
+
+// paint 回傳填滿與描邊的屬性
+func paint(style shape.Style) string {
+	fill := "none"
+	if style.FillStyle != "" {
+		fill = attr(style.FillStyle)
+	}
+	if style.StrokeStyle == "" || style.LineWidth <= 0 {
+		return fmt.Sprintf(` fill="%s" stroke="none"`, fill)
+	}
+	return fmt.Sprintf(` fill="%s" stroke="%s" stroke-width="%s"`,
+		fill, attr(style.StrokeStyle), num(style.LineWidth))
+}
+
+// transform 回傳以外框中心旋轉的 transform 屬性，沒有旋轉時回傳空字串
+func transform(o shape.Oriented) string {
+	b, angle := o.OrientedBounds()
+	if angle == 0 {
+		return ""
+	}
+	c := b.Center()
+	return fmt.Sprintf(` transform="rotate(%s %s %s)"`, num(angle*180/math.Pi), num(c.X), num(c.Y))
+}
+
+// points 將點列表格式化為 points 屬性
+func points(pts []shape.Point) string {
+	var buf bytes.Buffer
+	for i, p := range pts {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(num(p.X))
+		buf.WriteByte(',')
+		buf.WriteString(num(p.Y))
+	}
+	return buf.String()
+}
+
+// pathData 將線段的路徑片段轉為 <path> 的 d 屬性
+func pathData(l *shape.Line) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "M%s,%s", num(l.Points[0].X), num(l.Points[0].Y))
+	for _, s := range l.Segments() {
+		switch s.Kind {
+		case shape.SegmentQuadratic:
+			fmt.Fprintf(&buf, " Q%s,%s %s,%s", num(s.C1.X), num(s.C1.Y), num(s.End.X), num(s.End.Y))
+		case shape.SegmentCubic:
+			fmt.Fprintf(&buf, " C%s,%s %s,%s %s,%s",
+				num(s.C1.X), num(s.C1.Y), num(s.C2.X), num(s.C2.Y), num(s.End.X), num(s.End.Y))
+		default:
+			fmt.Fprintf(&buf, " L%s,%s", num(s.End.X), num(s.End.Y))
+		}
+	}
+	return buf.String()
+}
+
+// num 格式化數字，最多保留兩位小數
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+// attr 跳脫屬性值中的特殊字元
+func attr(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
@@ -0,0 +1,147 @@
+package svg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"canvas-demo/internal/canvas/layer"
+	"canvas-demo/internal/canvas/shape"
+)
+
+var view = shape.Bounds{X: 0, Y: 0, Width: 200, Height: 100}
+
+// marshal 輸出 SVG 並確認是格式正確的 XML
+func marshal(t *testing.T, shapes ...shape.Shape) string {
+	t.Helper()
+	out, err := Marshal(shapes, view)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	checkWellFormed(t, out)
+	return string(out)
+}
+
+func checkWellFormed(t *testing.T, out []byte) {
+	t.Helper()
+	d := xml.NewDecoder(bytes.NewReader(out))
+	for {
+		_, err := d.Token()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatalf("output is not well-formed XML: %v\n%s", err, out)
+		}
+	}
+}
+
+func contains(t *testing.T, out string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		if !strings.Contains(out, w) {
+			t.Errorf("output does not contain %q:\n%s", w, out)
+		}
+	}
+}
+
+func TestHeader(t *testing.T) {
+	out, err := Marshal(nil, shape.Bounds{X: -10.5, Y: 20, Width: 300, Height: 150.25})
+	if err != nil {
+		t.Fatal(err)
+	}
+	contains(t, string(out), `width="300" height="150.25" viewBox="-10.5 20 300 150.25"`)
+}
+
+func TestPolyline(t *testing.T) {
+	l := shape.NewLine(shape.Style{StrokeStyle: "#ff0000", LineWidth: 3})
+	l.AddPoint(shape.Point{X: 1, Y: 2})
+	l.AddPoint(shape.Point{X: 3.333, Y: 4})
+	l.AddPoint(shape.Point{X: 5, Y: 6})
+	contains(t, marshal(t, l),
+		`<polyline points="1,2 3.33,4 5,6" fill="none" stroke="#ff0000" stroke-width="3"/>`)
+
+	// 少於兩個點的線段與 Canvas 一樣不輸出
+	single := shape.NewLine(shape.Style{StrokeStyle: "#ff0000", LineWidth: 3})
+	single.AddPoint(shape.Point{X: 1, Y: 2})
+	if out := marshal(t, single); strings.Contains(out, "<polyline") {
+		t.Errorf("single point line was written:\n%s", out)
+	}
+}
+
+func TestSmoothLine(t *testing.T) {
+	l := shape.NewLine(shape.Style{StrokeStyle: "#000000", LineWidth: 2})
+	l.Smoothing = shape.SmoothCatmullRom
+	for _, p := range []shape.Point{{X: 0, Y: 0}, {X: 10, Y: 10}, {X: 20, Y: 0}} {
+		l.AddPoint(p)
+	}
+	contains(t, marshal(t, l), `<path d="M0,0 C`, `fill="none" stroke="#000000" stroke-width="2"`)
+}
+
+func TestText(t *testing.T) {
+	text := shape.NewText(shape.Point{X: 10, Y: 20}, shape.TextStyle{Font: "16px 'Noto Sans'", FillStyle: "#333333", Size: 16})
+	text.Content = `a < b & "c"`
+	out := marshal(t, text)
+	contains(t, out,
+		`<text x="10" y="20" style="font: 16px &#39;Noto Sans&#39;" fill="#333333">`,
+		`a &lt; b &amp; &#34;c&#34;</text>`)
+}
+
+func TestAttributeEscaping(t *testing.T) {
+	rc := shape.NewRect(shape.Style{StrokeStyle: `"><script>`, FillStyle: "a&b", LineWidth: 1})
+	rc.SetCorners(shape.Point{}, shape.Point{X: 10, Y: 10})
+	out := marshal(t, rc)
+	contains(t, out, `fill="a&amp;b"`, `stroke="&#34;&gt;&lt;script&gt;"`)
+	if strings.Contains(out, "<script>") {
+		t.Errorf("unescaped attribute:\n%s", out)
+	}
+}
+
+func TestPaint(t *testing.T) {
+	tests := []struct {
+		name  string
+		style shape.Style
+		want  string
+	}{
+		{"stroke only", shape.Style{StrokeStyle: "#000000", LineWidth: 2}, ` fill="none" stroke="#000000" stroke-width="2"`},
+		{"fill and stroke", shape.Style{StrokeStyle: "#000000", FillStyle: "#00ff00", LineWidth: 1.5}, ` fill="#00ff00" stroke="#000000" stroke-width="1.5"`},
+		{"fill only", shape.Style{FillStyle: "#00ff00"}, ` fill="#00ff00" stroke="none"`},
+		{"zero width", shape.Style{StrokeStyle: "#000000", FillStyle: "#00ff00"}, ` fill="#00ff00" stroke="none"`},
+	}
+	for _, tt := range tests {
+		if got := paint(tt.style); got != tt.want {
+			t.Errorf("%s: paint() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRotatedEllipse(t *testing.T) {
+	e := shape.NewEllipse(shape.Style{StrokeStyle: "#000000", LineWidth: 1})
+	e.SetCorners(shape.Point{X: 10, Y: 10}, shape.Point{X: 50, Y: 30})
+	e.Rotate(1.5707963267948966, shape.Point{X: 30, Y: 20})
+	contains(t, marshal(t, e),
+		`<ellipse cx="30" cy="20" rx="20" ry="10" fill="none" stroke="#000000" stroke-width="1" transform="rotate(90 30 20)"/>`)
+}
+
+func TestLayers(t *testing.T) {
+	rc := shape.NewRect(shape.Style{StrokeStyle: "#000000", LineWidth: 1})
+	rc.SetCorners(shape.Point{}, shape.Point{X: 10, Y: 10})
+	hidden := layer.New(1, "hidden")
+	hidden.Visible = false
+	hidden.Shapes = []shape.Shape{rc}
+	faded := layer.New(2, "faded")
+	faded.Opacity = 0.5
+	faded.Shapes = []shape.Shape{rc}
+
+	out, err := MarshalLayers([]*layer.Layer{hidden, faded}, view)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkWellFormed(t, out)
+	if n := strings.Count(string(out), "<rect"); n != 1 {
+		t.Errorf("got %d rects, want 1 (hidden layer skipped)", n)
+	}
+	contains(t, string(out), `<g opacity="0.5">`)
+}
@@ -0,0 +1,177 @@
+//go:build js && wasm
+
+package canvas
+
+import (
+	"math"
+
+	"canvas-demo/internal/canvas/layer"
+	"canvas-demo/internal/canvas/shape"
+)
+
+// 縮放的範圍與操作
+const (
+	minZoom        = 0.1
+	maxZoom        = 10.0
+	zoomStep       = 1.25   // 放大、縮小按鈕每次的倍率
+	wheelZoomSpeed = 0.0015 // 滾輪每個像素的縮放量
+	wheelLineSize  = 16.0   // 滾輪以行為單位時每行的像素
+	fitPadding     = 20.0   // 符合畫面時四周保留的螢幕像素
+)
+
+// viewport 是畫面的相機：畫布座標乘上 scale 再平移 (x, y) 就是螢幕座標
+type viewport struct {
+	scale float64
+	x, y  float64
+}
+
+// toWorld 將螢幕座標轉成畫布座標
+func (v viewport) toWorld(p shape.Point) shape.Point {
+	return shape.Point{X: (p.X - v.x) / v.scale, Y: (p.Y - v.y) / v.scale}
+}
+
+// screenLength 將螢幕上的長度換算成畫布座標
+func (v viewport) screenLength(length float64) float64 {
+	return length / v.scale
+}
+
+// Zoom 回傳目前的縮放倍率
+func (cm *CanvasManager) Zoom() float64 {
+	return cm.view.scale
+}
+
+// SetZoom 以畫面中心為準設置縮放倍率
+func (cm *CanvasManager) SetZoom(scale float64) {
+	cm.ZoomAt(scale/cm.view.scale, shape.Point{X: cm.width / 2, Y: cm.height / 2})
+}
+
+// ZoomIn 以畫面中心放大一級
+func (cm *CanvasManager) ZoomIn() {
+	cm.SetZoom(cm.view.scale * zoomStep)
+}
+
+// ZoomOut 以畫面中心縮小一級
+func (cm *CanvasManager) ZoomOut() {
+	cm.SetZoom(cm.view.scale / zoomStep)
+}
+
+// ZoomAt 以螢幕上的點 screen 為中心縮放 factor 倍，該點下的內容維持不動
+func (cm *CanvasManager) ZoomAt(factor float64, screen shape.Point) {
+	world := cm.view.toWorld(screen)
+	scale := math.Max(minZoom, math.Min(maxZoom, cm.view.scale*factor))
+	cm.setView(viewport{
+		scale: scale,
+		x:     screen.X - world.X*scale,
+		y:     screen.Y - world.Y*scale,
+	})
+}
+
+// Wheel 以滑鼠滾輪在游標位置縮放，deltaMode 為 WheelEvent 的單位（0 像素、1 行）
+func (cm *CanvasManager) Wheel(deltaY float64, deltaMode int, screen shape.Point) {
+	if deltaMode == 1 {
+		deltaY *= wheelLineSize
+	}
+	cm.ZoomAt(math.Exp(-deltaY*wheelZoomSpeed), screen)
+}
+
+// ResetZoom 回到原始大小與位置
+func (cm *CanvasManager) ResetZoom() {
+	cm.setView(viewport{scale: 1})
+}
+
+// ZoomToFit 縮放並移動畫面，讓可見圖層的所有形狀都在畫面中
+func (cm *CanvasManager) ZoomToFit() {
+	shapes := layer.VisibleShapes(cm.layers)
+	if len(shapes) == 0 {
+		cm.ResetZoom()
+		return
+	}
+	cm.zoomToBounds(shape.UnionBounds(shapes))
+}
+
+// ZoomToSelection 縮放並移動畫面，讓選取的形狀填滿畫面，沒有選取時不做任何事
+func (cm *CanvasManager) ZoomToSelection() {
+	if len(cm.selection) == 0 {
+		return
+	}
+	cm.zoomToBounds(cm.selectionBounds())
+}
+
+// zoomToBounds 讓範圍 b 置中並在四周保留 fitPadding 的情況下盡量放大
+func (cm *CanvasManager) zoomToBounds(b shape.Bounds) {
+	scale := cm.view.scale
+	if b.Width > 0 || b.Height > 0 {
+		scale = math.Min(
+			(cm.width-2*fitPadding)/math.Max(b.Width, 1),
+			(cm.height-2*fitPadding)/math.Max(b.Height, 1),
+		)
+	}
+	scale = math.Max(minZoom, math.Min(maxZoom, scale))
+
+	c := b.Center()
+	cm.setView(viewport{
+		scale: scale,
+		x:     cm.width/2 - c.X*scale,
+		y:     cm.height/2 - c.Y*scale,
+	})
+}
+
+// SetSpacePressed 記錄是否按住空白鍵，按住時拖曳會移動畫面
+func (cm *CanvasManager) SetSpacePressed(pressed bool) {
+	cm.spacePressed = pressed
+	cm.updateCursor()
+}
+
+// startPanning 開始拖曳移動畫面
+func (cm *CanvasManager) startPanning(screen shape.Point) {
+	cm.stopTextEdit()
+	cm.isPanning = true
+	cm.panLast = screen
+	cm.updateCursor()
+}
+
+// panTo 依螢幕上的移動量移動畫面
+func (cm *CanvasManager) panTo(screen shape.Point) {
+	v := cm.view
+	v.x += screen.X - cm.panLast.X
+	v.y += screen.Y - cm.panLast.Y
+	cm.panLast = screen
+	cm.setView(v)
+}
+
+// stopPanning 結束移動畫面
+func (cm *CanvasManager) stopPanning() {
+	cm.isPanning = false
+	cm.updateCursor()
+}
+
+// setView 更新相機，控制點與點選容差依 cm.view.scale 維持固定的螢幕大小
+func (cm *CanvasManager) setView(v viewport) {
+	// 輸入框的位置與大小不會跟著畫面更新，先結束編輯
+	cm.stopTextEdit()
+	cm.view = v
+	cm.redraw()
+}
+
+// visibleArea 回傳目前畫面所顯示的畫布範圍
+func (cm *CanvasManager) visibleArea() shape.Bounds {
+	topLeft := cm.view.toWorld(shape.Point{})
+	return shape.Bounds{
+		X:      topLeft.X,
+		Y:      topLeft.Y,
+		Width:  cm.view.screenLength(cm.width),
+		Height: cm.view.screenLength(cm.height),
+	}
+}
+
+// updateCursor 依是否可以移動畫面更新滑鼠游標
+func (cm *CanvasManager) updateCursor() {
+	cursor := ""
+	switch {
+	case cm.isPanning:
+		cursor = "grabbing"
+	case cm.spacePressed:
+		cursor = "grab"
+	}
+	cm.canvas.Get("style").Set("cursor", cursor)
+}
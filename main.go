@@ -3,9 +3,12 @@
 package main
 
 import (
+	"encoding/base64"
 	"syscall/js"
 
 	"canvas-demo/internal/canvas"
+	"canvas-demo/internal/canvas/grid"
+	"canvas-demo/internal/canvas/shape"
 )
 
 var canvasManager *canvas.CanvasManager
@@ -25,27 +28,89 @@ func main() {
 	js.Global().Set("drawing", js.FuncOf(drawing))
 	js.Global().Set("stopDrawing", js.FuncOf(stopDrawing))
 	js.Global().Set("deleteSelectedShape", js.FuncOf(deleteSelectedShape))
+	js.Global().Set("groupSelected", js.FuncOf(groupSelected))
+	js.Global().Set("ungroupSelected", js.FuncOf(ungroupSelected))
+	js.Global().Set("copySelected", js.FuncOf(copySelected))
+	js.Global().Set("cutSelected", js.FuncOf(cutSelected))
+	js.Global().Set("pasteClipboard", js.FuncOf(pasteClipboard))
+	js.Global().Set("duplicateSelected", js.FuncOf(duplicateSelected))
+	js.Global().Set("pointerLeave", js.FuncOf(pointerLeave))
+	js.Global().Set("wheelZoom", js.FuncOf(wheelZoom))
+	js.Global().Set("setSpacePressed", js.FuncOf(setSpacePressed))
+	js.Global().Set("getZoom", js.FuncOf(getZoom))
+	js.Global().Set("zoomIn", js.FuncOf(zoomIn))
+	js.Global().Set("zoomOut", js.FuncOf(zoomOut))
+	js.Global().Set("resetZoom", js.FuncOf(resetZoom))
+	js.Global().Set("zoomToFit", js.FuncOf(zoomToFit))
+	js.Global().Set("zoomToSelection", js.FuncOf(zoomToSelection))
+	js.Global().Set("setBackground", js.FuncOf(setBackground))
+	js.Global().Set("setMinimapVisible", js.FuncOf(setMinimapVisible))
+	js.Global().Set("getGrid", js.FuncOf(getGrid))
+	js.Global().Set("getFrameStats", js.FuncOf(getFrameStats))
+	js.Global().Set("resetFrameStats", js.FuncOf(resetFrameStats))
+	js.Global().Set("setGrid", js.FuncOf(setGrid))
+	js.Global().Set("bringForward", js.FuncOf(bringForward))
+	js.Global().Set("sendBackward", js.FuncOf(sendBackward))
+	js.Global().Set("bringToFront", js.FuncOf(bringToFront))
+	js.Global().Set("sendToBack", js.FuncOf(sendToBack))
+	js.Global().Set("alignSelected", js.FuncOf(alignSelected))
+	js.Global().Set("distributeSelected", js.FuncOf(distributeSelected))
 	js.Global().Set("setCurrentTool", js.FuncOf(setCurrentTool))
+	js.Global().Set("setFillStyle", js.FuncOf(setFillStyle))
+	js.Global().Set("setStrokeEnabled", js.FuncOf(setStrokeEnabled))
+	js.Global().Set("setArrowMarkers", js.FuncOf(setArrowMarkers))
+	js.Global().Set("setEraserRadius", js.FuncOf(setEraserRadius))
+	js.Global().Set("setEraserMode", js.FuncOf(setEraserMode))
+	js.Global().Set("setSimplifyTolerance", js.FuncOf(setSimplifyTolerance))
+	js.Global().Set("setSmoothing", js.FuncOf(setSmoothing))
+	js.Global().Set("setPenStyle", js.FuncOf(setPenStyle))
+	js.Global().Set("undo", js.FuncOf(undo))
+	js.Global().Set("redo", js.FuncOf(redo))
+	js.Global().Set("setHistoryLimit", js.FuncOf(setHistoryLimit))
+	js.Global().Set("saveDocument", js.FuncOf(saveDocument))
+	js.Global().Set("loadDocument", js.FuncOf(loadDocument))
+	js.Global().Set("exportSVG", js.FuncOf(exportSVG))
+	js.Global().Set("exportPNG", js.FuncOf(exportPNG))
+	js.Global().Set("getLayers", js.FuncOf(getLayers))
+	js.Global().Set("addLayer", js.FuncOf(addLayer))
+	js.Global().Set("removeLayer", js.FuncOf(removeLayer))
+	js.Global().Set("moveLayer", js.FuncOf(moveLayer))
+	js.Global().Set("renameLayer", js.FuncOf(renameLayer))
+	js.Global().Set("setLayerVisible", js.FuncOf(setLayerVisible))
+	js.Global().Set("setLayerLocked", js.FuncOf(setLayerLocked))
+	js.Global().Set("setLayerOpacity", js.FuncOf(setLayerOpacity))
+	js.Global().Set("setActiveLayer", js.FuncOf(setActiveLayer))
 
 	<-c
 }
 
+// startDrawing 處理 pointerdown
 func startDrawing(this js.Value, args []js.Value) interface{} {
 	event := args[0]
-	x, y := canvasManager.GetMousePosition(event)
-	canvasManager.StartDrawing(x, y)
+	canvasManager.SetModifiers(modifiersFromEvent(event))
+	canvasManager.PointerDown(canvasManager.ReadPointer(event))
 	return nil
 }
 
+// drawing 處理 pointermove，包含瀏覽器合併的輸入
 func drawing(this js.Value, args []js.Value) interface{} {
 	event := args[0]
-	x, y := canvasManager.GetMousePosition(event)
-	canvasManager.Draw(x, y)
+	canvasManager.SetModifiers(modifiersFromEvent(event))
+	canvasManager.PointerMove(canvasManager.CoalescedPointers(event))
 	return nil
 }
 
+// modifiersFromEvent 讀取指標事件中的修飾鍵
+func modifiersFromEvent(event js.Value) canvas.Modifiers {
+	return canvas.Modifiers{
+		Shift: event.Get("shiftKey").Bool(),
+		Alt:   event.Get("altKey").Bool(),
+	}
+}
+
+// stopDrawing 處理 pointerup 與 pointercancel
 func stopDrawing(this js.Value, args []js.Value) interface{} {
-	canvasManager.StopDrawing()
+	canvasManager.PointerUp(canvasManager.ReadPointer(args[0]))
 	return nil
 }
 
@@ -54,6 +119,191 @@ func deleteSelectedShape(this js.Value, args []js.Value) interface{} {
 	return nil
 }
 
+func groupSelected(this js.Value, args []js.Value) interface{} {
+	canvasManager.GroupSelected()
+	return nil
+}
+
+func ungroupSelected(this js.Value, args []js.Value) interface{} {
+	canvasManager.UngroupSelected()
+	return nil
+}
+
+// copySelected 回傳選取形狀的 JSON 文件，沒有選取或失敗時回傳 null
+func copySelected(this js.Value, args []js.Value) interface{} {
+	return clipboardResult(canvasManager.CopySelected())
+}
+
+// cutSelected 回傳選取形狀的 JSON 文件並刪除它們，沒有選取或失敗時回傳 null
+func cutSelected(this js.Value, args []js.Value) interface{} {
+	return clipboardResult(canvasManager.CutSelected())
+}
+
+// clipboardResult 將複製的結果轉為回傳給 JS 的值
+func clipboardResult(data string, err error) interface{} {
+	if err != nil {
+		js.Global().Get("console").Call("error", err.Error())
+		return nil
+	}
+	if data == "" {
+		return nil
+	}
+	return data
+}
+
+// pasteClipboard 貼上剪貼簿中的 JSON 文件，成功時回傳 null，失敗時回傳錯誤訊息
+func pasteClipboard(this js.Value, args []js.Value) interface{} {
+	if len(args) == 0 {
+		return "paste: no data"
+	}
+	return errorResult(canvasManager.Paste(args[0].String()))
+}
+
+func duplicateSelected(this js.Value, args []js.Value) interface{} {
+	canvasManager.DuplicateSelected()
+	return nil
+}
+
+func pointerLeave(this js.Value, args []js.Value) interface{} {
+	canvasManager.PointerLeave()
+	return nil
+}
+
+// wheelZoom 處理 wheel 事件，以游標位置為中心縮放
+func wheelZoom(this js.Value, args []js.Value) interface{} {
+	event := args[0]
+	canvasManager.Wheel(event.Get("deltaY").Float(), event.Get("deltaMode").Int(), canvasManager.ScreenPosition(event))
+	return nil
+}
+
+func setSpacePressed(this js.Value, args []js.Value) interface{} {
+	if len(args) > 0 {
+		canvasManager.SetSpacePressed(args[0].Bool())
+	}
+	return nil
+}
+
+func getZoom(this js.Value, args []js.Value) interface{} {
+	return canvasManager.Zoom()
+}
+
+func zoomIn(this js.Value, args []js.Value) interface{} {
+	canvasManager.ZoomIn()
+	return nil
+}
+
+func zoomOut(this js.Value, args []js.Value) interface{} {
+	canvasManager.ZoomOut()
+	return nil
+}
+
+func resetZoom(this js.Value, args []js.Value) interface{} {
+	canvasManager.ResetZoom()
+	return nil
+}
+
+func zoomToFit(this js.Value, args []js.Value) interface{} {
+	canvasManager.ZoomToFit()
+	return nil
+}
+
+func zoomToSelection(this js.Value, args []js.Value) interface{} {
+	canvasManager.ZoomToSelection()
+	return nil
+}
+
+// setBackground 設置背景圖樣（none、dots 或 lines），成功時回傳 null，失敗時回傳錯誤訊息
+func setBackground(this js.Value, args []js.Value) interface{} {
+	if len(args) == 0 {
+		return "setBackground: expected pattern"
+	}
+	return errorResult(canvasManager.SetBackground(args[0].String()))
+}
+
+func setMinimapVisible(this js.Value, args []js.Value) interface{} {
+	if len(args) > 0 {
+		canvasManager.SetMinimapVisible(args[0].Bool())
+	}
+	return nil
+}
+
+// getGrid 回傳目前的格線設定，載入文件後用來更新介面
+func getGrid(this js.Value, args []js.Value) interface{} {
+	g := canvasManager.Grid()
+	return map[string]interface{}{
+		"spacing":      g.Spacing,
+		"subdivisions": g.Subdivisions,
+		"color":        g.Color,
+		"visible":      g.Visible,
+		"snap":         g.Snap,
+	}
+}
+
+// getFrameStats 回傳繪製效能的統計，時間的單位為毫秒
+func getFrameStats(this js.Value, args []js.Value) interface{} {
+	s := canvasManager.FrameStats()
+	return map[string]interface{}{
+		"frames":        s.Frames,
+		"fullRedraws":   s.FullRedraws,
+		"fps":           s.FPS,
+		"frameTime":     s.FrameTime,
+		"maxFrameTime":  s.MaxFrameTime,
+		"lastFrameTime": s.LastFrameTime,
+	}
+}
+
+func resetFrameStats(this js.Value, args []js.Value) interface{} {
+	canvasManager.ResetFrameStats()
+	return nil
+}
+
+func setGrid(this js.Value, args []js.Value) interface{} {
+	if len(args) < 5 {
+		return "setGrid: expected spacing, subdivisions, color, visible and snap"
+	}
+	return errorResult(canvasManager.SetGrid(grid.Grid{
+		Spacing:      args[0].Float(),
+		Subdivisions: args[1].Int(),
+		Color:        args[2].String(),
+		Visible:      args[3].Bool(),
+		Snap:         args[4].Bool(),
+	}))
+}
+
+func bringForward(this js.Value, args []js.Value) interface{} {
+	canvasManager.BringForward()
+	return nil
+}
+
+func sendBackward(this js.Value, args []js.Value) interface{} {
+	canvasManager.SendBackward()
+	return nil
+}
+
+func bringToFront(this js.Value, args []js.Value) interface{} {
+	canvasManager.BringToFront()
+	return nil
+}
+
+func sendToBack(this js.Value, args []js.Value) interface{} {
+	canvasManager.SendToBack()
+	return nil
+}
+
+func alignSelected(this js.Value, args []js.Value) interface{} {
+	if len(args) == 0 {
+		return "alignSelected: expected edge"
+	}
+	return errorResult(canvasManager.AlignSelected(args[0].String()))
+}
+
+func distributeSelected(this js.Value, args []js.Value) interface{} {
+	if len(args) == 0 {
+		return "distributeSelected: expected direction"
+	}
+	return errorResult(canvasManager.DistributeSelected(args[0].String()))
+}
+
 func setCurrentTool(this js.Value, args []js.Value) interface{} {
 	if len(args) > 0 {
 		tool := args[0].String()
@@ -61,3 +311,216 @@ func setCurrentTool(this js.Value, args []js.Value) interface{} {
 	}
 	return nil
 }
+
+func setFillStyle(this js.Value, args []js.Value) interface{} {
+	if len(args) > 0 {
+		canvasManager.SetFillStyle(args[0].String())
+	}
+	return nil
+}
+
+func setStrokeEnabled(this js.Value, args []js.Value) interface{} {
+	if len(args) > 0 {
+		canvasManager.SetStrokeEnabled(args[0].Bool())
+	}
+	return nil
+}
+
+// setArrowMarkers 設置箭頭工具兩端的標記，成功時回傳 null，失敗時回傳錯誤訊息
+func setArrowMarkers(this js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return "setArrowMarkers: expected start and end markers"
+	}
+	return errorResult(canvasManager.SetArrowMarkers(args[0].String(), args[1].String()))
+}
+
+func setEraserRadius(this js.Value, args []js.Value) interface{} {
+	if len(args) > 0 {
+		canvasManager.SetEraserRadius(args[0].Float())
+	}
+	return nil
+}
+
+// setEraserMode 設置橡皮擦模式（split 或 stroke），成功時回傳 null，失敗時回傳錯誤訊息
+func setEraserMode(this js.Value, args []js.Value) interface{} {
+	if len(args) == 0 {
+		return "setEraserMode: expected mode"
+	}
+	return errorResult(canvasManager.SetEraserMode(args[0].String()))
+}
+
+func setSimplifyTolerance(this js.Value, args []js.Value) interface{} {
+	if len(args) > 0 {
+		canvasManager.SetSimplifyTolerance(args[0].Float())
+	}
+	return nil
+}
+
+// setSmoothing 設置筆畫的平滑方式（none、catmull-rom 或 quadratic），成功時回傳 null，失敗時回傳錯誤訊息
+func setSmoothing(this js.Value, args []js.Value) interface{} {
+	if len(args) == 0 {
+		return "setSmoothing: expected smoothing"
+	}
+	return errorResult(canvasManager.SetSmoothing(args[0].String()))
+}
+
+// setPenStyle 設置觸控筆筆畫的寬度曲線（minWidth, maxWidth, thinning, taperStart, taperEnd），
+// 成功時回傳 null，失敗時回傳錯誤訊息
+func setPenStyle(this js.Value, args []js.Value) interface{} {
+	if len(args) < 5 {
+		return "setPenStyle: expected minWidth, maxWidth, thinning, taperStart and taperEnd"
+	}
+	return errorResult(canvasManager.SetPenStyle(shape.PenStyle{
+		MinWidth:   args[0].Float(),
+		MaxWidth:   args[1].Float(),
+		Thinning:   args[2].Float(),
+		TaperStart: args[3].Float(),
+		TaperEnd:   args[4].Float(),
+	}))
+}
+
+func undo(this js.Value, args []js.Value) interface{} {
+	canvasManager.Undo()
+	return nil
+}
+
+func redo(this js.Value, args []js.Value) interface{} {
+	canvasManager.Redo()
+	return nil
+}
+
+func setHistoryLimit(this js.Value, args []js.Value) interface{} {
+	if len(args) > 0 {
+		canvasManager.SetHistoryLimit(args[0].Int())
+	}
+	return nil
+}
+
+// saveDocument 回傳 JSON 文件字串，失敗時回傳 null
+func saveDocument(this js.Value, args []js.Value) interface{} {
+	data, err := canvasManager.SaveDocument()
+	if err != nil {
+		js.Global().Get("console").Call("error", err.Error())
+		return nil
+	}
+	return data
+}
+
+// loadDocument 載入 JSON 文件，成功時回傳 null，失敗時回傳錯誤訊息
+func loadDocument(this js.Value, args []js.Value) interface{} {
+	if len(args) == 0 {
+		return "document: no data"
+	}
+	return errorResult(canvasManager.LoadDocument(args[0].String()))
+}
+
+// exportSVG 回傳 SVG 字串，失敗時回傳 null
+func exportSVG(this js.Value, args []js.Value) interface{} {
+	data, err := canvasManager.ExportSVG()
+	if err != nil {
+		js.Global().Get("console").Call("error", err.Error())
+		return nil
+	}
+	return data
+}
+
+// exportPNG 回傳 PNG 的 data URL，失敗時回傳 null
+func exportPNG(this js.Value, args []js.Value) interface{} {
+	data, err := canvasManager.ExportPNG()
+	if err != nil {
+		js.Global().Get("console").Call("error", err.Error())
+		return nil
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+// getLayers 回傳圖層資訊的陣列，依由下往上的順序
+func getLayers(this js.Value, args []js.Value) interface{} {
+	layers := canvasManager.Layers()
+	result := make([]interface{}, len(layers))
+	for i, l := range layers {
+		result[i] = map[string]interface{}{
+			"id":         l.ID,
+			"name":       l.Name,
+			"visible":    l.Visible,
+			"locked":     l.Locked,
+			"opacity":    l.Opacity,
+			"active":     l.Active,
+			"shapeCount": l.ShapeCount,
+		}
+	}
+	return result
+}
+
+// addLayer 新增圖層並回傳其 ID，可以指定名稱
+func addLayer(this js.Value, args []js.Value) interface{} {
+	name := ""
+	if len(args) > 0 && args[0].Type() == js.TypeString {
+		name = args[0].String()
+	}
+	return canvasManager.AddLayer(name)
+}
+
+// removeLayer 刪除圖層，成功時回傳 null，失敗時回傳錯誤訊息
+func removeLayer(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return "removeLayer: expected layer id"
+	}
+	return errorResult(canvasManager.RemoveLayer(args[0].Int()))
+}
+
+// moveLayer 將圖層移到指定位置（0 為最下層），成功時回傳 null，失敗時回傳錯誤訊息
+func moveLayer(this js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return "moveLayer: expected layer id and index"
+	}
+	return errorResult(canvasManager.MoveLayer(args[0].Int(), args[1].Int()))
+}
+
+// renameLayer 重新命名圖層，成功時回傳 null，失敗時回傳錯誤訊息
+func renameLayer(this js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return "renameLayer: expected layer id and name"
+	}
+	return errorResult(canvasManager.RenameLayer(args[0].Int(), args[1].String()))
+}
+
+// setLayerVisible 顯示或隱藏圖層，成功時回傳 null，失敗時回傳錯誤訊息
+func setLayerVisible(this js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return "setLayerVisible: expected layer id and visibility"
+	}
+	return errorResult(canvasManager.SetLayerVisible(args[0].Int(), args[1].Bool()))
+}
+
+// setLayerLocked 鎖定或解除鎖定圖層，成功時回傳 null，失敗時回傳錯誤訊息
+func setLayerLocked(this js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return "setLayerLocked: expected layer id and lock state"
+	}
+	return errorResult(canvasManager.SetLayerLocked(args[0].Int(), args[1].Bool()))
+}
+
+// setLayerOpacity 設置圖層透明度（0 到 1），成功時回傳 null，失敗時回傳錯誤訊息
+func setLayerOpacity(this js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return "setLayerOpacity: expected layer id and opacity"
+	}
+	return errorResult(canvasManager.SetLayerOpacity(args[0].Int(), args[1].Float()))
+}
+
+// setActiveLayer 設置新形狀要加入的圖層，成功時回傳 null，失敗時回傳錯誤訊息
+func setActiveLayer(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return "setActiveLayer: expected layer id"
+	}
+	return errorResult(canvasManager.SetActiveLayer(args[0].Int()))
+}
+
+// errorResult 將 error 轉為回傳給 JS 的值：成功時為 null，失敗時為錯誤訊息
+func errorResult(err error) interface{} {
+	if err != nil {
+		return err.Error()
+	}
+	return nil
+}